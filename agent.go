@@ -0,0 +1,109 @@
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAgentPort is the port of passive checks
+	defaultAgentPort = "10050"
+	// defaultAgentTimeout bounds each check, as the 3s default of zabbix_get
+	defaultAgentTimeout = 3 * time.Second
+)
+
+// AgentConfig configures an AgentClient.
+type AgentConfig struct {
+	// Timeout bounds each check, 3s if zero
+	Timeout time.Duration
+	TLSOptions
+}
+
+// AgentClient runs passive checks on Zabbix agents like zabbix_get, to try item keys before creating items.
+// https://www.zabbix.com/documentation/current/en/manual/appendix/items/activepassive
+type AgentClient struct {
+	config AgentConfig
+}
+
+// NewAgentClient Creates an AgentClient, checking its TLS options.
+func NewAgentClient(c AgentConfig) (*AgentClient, error) {
+	if err := c.TLSOptions.check(); err != nil {
+		return nil, err
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultAgentTimeout
+	}
+	return &AgentClient{config: c}, nil
+}
+
+// ItemNotSupportedError is returned when the agent answers ZBX_NOTSUPPORTED for a key
+type ItemNotSupportedError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ItemNotSupportedError) Error() string {
+	return fmt.Sprintf("%s: not supported: %s", e.Key, e.Reason)
+}
+
+// agentAddr returns the address of an agent interface, by IP or DNS according to UseIP
+func agentAddr(iface HostInterface) (string, error) {
+	host := iface.DNS
+	if iface.UseIP != "0" {
+		host = iface.IP
+	}
+	if host == "" {
+		return "", fmt.Errorf("interface %s has no address", iface.InterfaceID)
+	}
+	port := iface.Port
+	if port == "" {
+		port = defaultAgentPort
+	}
+	if strings.Contains(host+port, "{") {
+		return "", fmt.Errorf("interface %s holds unresolved macros: %s:%s", iface.InterfaceID, host, port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// Get Gets the value of key from the agent of iface.
+// A key the agent does not support gives an ItemNotSupportedError.
+func (c *AgentClient) Get(iface HostInterface, key string) (string, error) {
+	return c.GetContext(context.Background(), iface, key)
+}
+
+// GetContext is like Get but uses ctx for the connection.
+func (c *AgentClient) GetContext(ctx context.Context, iface HostInterface, key string) (value string, err error) {
+	if iface.Type != "" && iface.Type != Agent {
+		return "", fmt.Errorf("interface %s is not an agent interface", iface.InterfaceID)
+	}
+	addr, err := agentAddr(iface)
+	if err != nil {
+		return
+	}
+	return c.GetAddr(ctx, addr, key)
+}
+
+// GetAddr is like GetContext but connects to addr, port 10050 if missing.
+func (c *AgentClient) GetAddr(ctx context.Context, addr, key string) (value string, err error) {
+	addr = withDefaultPort(addr, defaultAgentPort)
+	response, err := c.config.exchange(ctx, addr, c.config.Timeout, []byte(key), false)
+	if err != nil {
+		return
+	}
+	return parseAgentResponse(key, response)
+}
+
+// parseAgentResponse returns the value of a passive check, or the reason of ZBX_NOTSUPPORTED and ZBX_ERROR answers
+func parseAgentResponse(key string, b []byte) (string, error) {
+	if reason, ok := bytes.CutPrefix(b, []byte("ZBX_NOTSUPPORTED")); ok {
+		return "", &ItemNotSupportedError{key, string(bytes.TrimPrefix(reason, []byte{0}))}
+	}
+	if reason, ok := bytes.CutPrefix(b, []byte("ZBX_ERROR")); ok {
+		return "", fmt.Errorf("%s: agent error: %s", key, bytes.TrimPrefix(reason, []byte{0}))
+	}
+	return string(b), nil
+}
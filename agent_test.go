@@ -0,0 +1,92 @@
+package zabbix_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func agentInterface(t *testing.T, addr string) zapi.HostInterface {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zapi.HostInterface{InterfaceID: "1", IP: host, DNS: "agent.invalid", UseIP: "1", Port: port, Type: zapi.Agent}
+}
+
+func TestAgentGet(t *testing.T) {
+	addr, requests := zbxdServer(t, func(key []byte) []byte {
+		switch string(key) {
+		case "agent.ping":
+			return []byte("1")
+		case "system.uname":
+			return []byte("Linux web 6.1.0")
+		case "vfs.fs.size[/,pfree]":
+			return []byte("ZBX_ERROR\x00Cannot obtain filesystem information.")
+		}
+		return []byte("ZBX_NOTSUPPORTED\x00Unsupported item key.")
+	}, true)
+
+	c, err := zapi.NewAgentClient(zapi.AgentConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	iface := agentInterface(t, addr)
+	v, err := c.Get(iface, "agent.ping")
+	if err != nil || v != "1" {
+		t.Errorf("Expected 1, got %q, %v", v, err)
+	}
+	if v, err = c.GetAddr(context.Background(), addr, "system.uname"); err != nil || v != "Linux web 6.1.0" {
+		t.Errorf("Bad uname %q, %v", v, err)
+	}
+	if string(requests()[0]) != "agent.ping" {
+		t.Errorf("Bad request %q", requests()[0])
+	}
+
+	_, err = c.Get(iface, "no.such.key")
+	var e *zapi.ItemNotSupportedError
+	if !errors.As(err, &e) || e.Key != "no.such.key" || e.Reason != "Unsupported item key." {
+		t.Errorf("Expected an ItemNotSupportedError, got %v", err)
+	}
+	if _, err = c.Get(iface, "vfs.fs.size[/,pfree]"); err == nil || errors.As(err, &e) {
+		t.Errorf("Expected an agent error, got %v", err)
+	}
+}
+
+func TestAgentGetErrors(t *testing.T) {
+	c, err := zapi.NewAgentClient(zapi.AgentConfig{Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iface := range []zapi.HostInterface{
+		{InterfaceID: "1", UseIP: "1", Port: "10050"},
+		{InterfaceID: "2", IP: "127.0.0.1", UseIP: "1", Port: "{$AGENT.PORT}"},
+		{InterfaceID: "3", IP: "127.0.0.1", UseIP: "1", Port: "161", Type: zapi.SNMP},
+	} {
+		if _, err = c.Get(iface, "agent.ping"); err == nil {
+			t.Errorf("Expected an error for interface %s", iface.InterfaceID)
+		}
+	}
+
+	// an agent that never answers
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		if conn, err := l.Accept(); err == nil {
+			defer conn.Close()
+			time.Sleep(time.Second)
+		}
+	}()
+	start := time.Now()
+	_, err = c.Get(agentInterface(t, l.Addr().String()), "agent.ping")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected a timeout, got %v after %s", err, time.Since(start))
+	}
+}
@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 // Application represent Zabbix application object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/application/object
 type Application struct {
@@ -12,39 +14,41 @@ type Application struct {
 // Applications is an array of Application
 type Applications []Application
 
+// ApplicationResource describes application objects
+var ApplicationResource = &Resource[Application]{
+	Object:  "application",
+	IDField: "applicationid",
+}
+
 // ApplicationsGet Wrapper for application.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/application/get
 func (api *API) ApplicationsGet(params Params) (res Applications, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("application.get", params, &res)
-	if err != nil {
-		return
-	}
+	return api.ApplicationsGetContext(context.Background(), params)
+}
 
-	return
+// ApplicationsGetContext is like ApplicationsGet but uses ctx for the request.
+func (api *API) ApplicationsGetContext(ctx context.Context, params Params) (res Applications, err error) {
+	return ApplicationResource.Get(ctx, api, params)
 }
 
 // ApplicationGetByID Gets application by Id only if there is exactly 1 matching application.
 func (api *API) ApplicationGetByID(id string) (res *Application, err error) {
-	apps, err := api.ApplicationsGet(Params{"applicationids": id})
-	if err != nil {
-		return
-	}
+	return api.ApplicationGetByIDContext(context.Background(), id)
+}
 
-	if len(apps) == 1 {
-		res = &apps[0]
-	} else {
-		e := ExpectedOneResult(len(apps))
-		err = &e
-	}
-	return
+// ApplicationGetByIDContext is like ApplicationGetByID but uses ctx for the request.
+func (api *API) ApplicationGetByIDContext(ctx context.Context, id string) (res *Application, err error) {
+	return ApplicationResource.GetOne(ctx, api, id)
 }
 
 // ApplicationGetByHostIDAndName Gets application by host Id and name only if there is exactly 1 matching application.
 func (api *API) ApplicationGetByHostIDAndName(hostID, name string) (res *Application, err error) {
-	apps, err := api.ApplicationsGet(Params{"hostids": hostID, "filter": map[string]string{"name": name}})
+	return api.ApplicationGetByHostIDAndNameContext(context.Background(), hostID, name)
+}
+
+// ApplicationGetByHostIDAndNameContext is like ApplicationGetByHostIDAndName but uses ctx for the request.
+func (api *API) ApplicationGetByHostIDAndNameContext(ctx context.Context, hostID, name string) (res *Application, err error) {
+	apps, err := api.ApplicationsGetContext(ctx, Params{"hostids": hostID, "filter": map[string]string{"name": name}})
 	if err != nil {
 		return
 	}
@@ -61,49 +65,33 @@ func (api *API) ApplicationGetByHostIDAndName(hostID, name string) (res *Applica
 // ApplicationsCreate Wrapper for application.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/application/create
 func (api *API) ApplicationsCreate(apps Applications) (err error) {
-	response, err := api.CallWithError("application.create", apps)
-	if err != nil {
-		return
-	}
+	return api.ApplicationsCreateContext(context.Background(), apps)
+}
 
-	result := response.Result.(map[string]interface{})
-	applicationids := result["applicationids"].([]interface{})
-	for i, id := range applicationids {
-		apps[i].ApplicationID = id.(string)
-	}
-	return
+// ApplicationsCreateContext is like ApplicationsCreate but uses ctx for the request.
+func (api *API) ApplicationsCreateContext(ctx context.Context, apps Applications) (err error) {
+	return ApplicationResource.Create(ctx, api, apps)
 }
 
 // ApplicationsDelete Wrapper for application.delete:
 // Cleans ApplicationID in all apps elements if call succeed.
 // https://www.zabbix.com/documentation/2.2/manual/appendix/api/application/delete
 func (api *API) ApplicationsDelete(apps Applications) (err error) {
-	ids := make([]string, len(apps))
-	for i, app := range apps {
-		ids[i] = app.ApplicationID
-	}
+	return api.ApplicationsDeleteContext(context.Background(), apps)
+}
 
-	err = api.ApplicationsDeleteByIds(ids)
-	if err == nil {
-		for i := range apps {
-			apps[i].ApplicationID = ""
-		}
-	}
-	return
+// ApplicationsDeleteContext is like ApplicationsDelete but uses ctx for the request.
+func (api *API) ApplicationsDeleteContext(ctx context.Context, apps Applications) (err error) {
+	return ApplicationResource.Delete(ctx, api, apps)
 }
 
 // ApplicationsDeleteByIds Wrapper for application.delete
 // https://www.zabbix.com/documentation/2.2/manual/appendix/api/application/delete
 func (api *API) ApplicationsDeleteByIds(ids []string) (err error) {
-	response, err := api.CallWithError("application.delete", ids)
-	if err != nil {
-		return
-	}
+	return api.ApplicationsDeleteByIdsContext(context.Background(), ids)
+}
 
-	result := response.Result.(map[string]interface{})
-	applicationids := result["applicationids"].([]interface{})
-	if len(ids) != len(applicationids) {
-		err = &ExpectedMore{len(ids), len(applicationids)}
-	}
-	return
+// ApplicationsDeleteByIdsContext is like ApplicationsDeleteByIds but uses ctx for the request.
+func (api *API) ApplicationsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return ApplicationResource.DeleteByIDs(ctx, api, ids)
 }
@@ -10,8 +10,12 @@ import (
 )
 
 func CreateApplication(host *zapi.Host, t *testing.T) *zapi.Application {
+	api := getAPI(t)
+	if !api.Supports(zapi.CapApplications) {
+		t.Skip("applications were removed in Zabbix 5.4")
+	}
 	apps := zapi.Applications{{HostID: host.HostID, Name: fmt.Sprintf("App %d for %s", rand.Int(), host.Host)}}
-	err := getAPI(t).ApplicationsCreate(apps)
+	err := api.ApplicationsCreate(apps)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -1,23 +1,37 @@
 package zabbix_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
 )
 
 var (
-	_host string
-	_api  *zapi.API
+	_host     string
+	_api      *zapi.API
+	_cassette *zapi.Cassette
 )
 
 func init() {
+	if os.Getenv("TEST_ZABBIX_RECORD") != "" || os.Getenv("TEST_ZABBIX_REPLAY") != "" {
+		// recorded params must not depend on the machine or the run
+		rand.Seed(1)
+		_host = "cassette-testing"
+		return
+	}
 	rand.Seed(time.Now().UnixNano())
 
 	var err error
@@ -26,10 +40,18 @@ func init() {
 		log.Fatal(err)
 	}
 	_host += "-testing"
+}
 
-	if os.Getenv("TEST_ZABBIX_URL") == "" {
-		log.Fatal("Set environment variables TEST_ZABBIX_URL (and optionally TEST_ZABBIX_USER and TEST_ZABBIX_PASSWORD)")
+// TestMain saves the cassette recorded with TEST_ZABBIX_RECORD
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if path := os.Getenv("TEST_ZABBIX_RECORD"); path != "" && _cassette != nil {
+		if err := _cassette.Save(path); err != nil {
+			log.Print(err)
+			code = 1
+		}
 	}
+	os.Exit(code)
 }
 
 func getHost() string {
@@ -42,12 +64,30 @@ func getAPI(t *testing.T) *zapi.API {
 	}
 
 	url, user, password := os.Getenv("TEST_ZABBIX_URL"), os.Getenv("TEST_ZABBIX_USER"), os.Getenv("TEST_ZABBIX_PASSWORD")
+	if url == "" {
+		// no real server, run against the in-process fake
+		srv := zabbixtest.NewServer(zabbixtest.Config{Version: os.Getenv("TEST_ZABBIX_VERSION")})
+		url, user, password = srv.URL, zabbixtest.DefaultUser, zabbixtest.DefaultPassword
+	}
 
 	// Zabbix client connection configuration
 	var c zapi.Config
 	c.Url = url
 
-	_api = zapi.NewAPI(c)
+	var err error
+	if path := os.Getenv("TEST_ZABBIX_RECORD"); path != "" {
+		_cassette = zapi.NewCassette()
+		c.Middleware = append(c.Middleware, _cassette.Record())
+	} else if path := os.Getenv("TEST_ZABBIX_REPLAY"); path != "" {
+		if _cassette, err = zapi.LoadCassette(path); err != nil {
+			t.Fatal(err)
+		}
+		c.Transport = _cassette
+	}
+	_api, err = zapi.NewAPI(c)
+	if err != nil {
+		t.Fatal(err)
+	}
 	_api.SetClient(http.DefaultClient)
 	v := os.Getenv("TEST_ZABBIX_VERBOSE")
 	if v != "" && v != "0" {
@@ -89,3 +129,65 @@ func TestVersion(t *testing.T) {
 		t.Errorf("Unexpected version: %s", v)
 	}
 }
+
+func TestCallContextDeadline(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int32  `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "APIInfo.version" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","result":"5.0.0","id":%d}`, req.ID)
+			return
+		}
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(block)
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = api.HostsGetContext(ctx, zapi.Params{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+// rpcHandler answers a single JSON-RPC call with either a result or an API error
+type rpcHandler func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error)
+
+// newRPCServer starts a JSON-RPC server reporting version and delegating other calls to h
+func newRPCServer(t *testing.T, version string, h rpcHandler) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Auth   string          `json:"auth"`
+			ID     int32           `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("Bad request: %s", err)
+			return
+		}
+
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if strings.EqualFold(req.Method, "apiinfo.version") {
+			res["result"] = version
+		} else if result, e := h(r, req.Method, req.Params, req.Auth); e != nil {
+			res["error"] = e
+		} else {
+			res["result"] = result
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+}
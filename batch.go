@@ -0,0 +1,169 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// Batch queues several API calls and sends them as a single JSON-RPC batch request.
+// A Batch is not safe for concurrent use.
+type Batch struct {
+	api      *API
+	calls    []*BatchCall
+	endpoint string
+}
+
+// BatchCall is a single call queued in a Batch.
+// Result and Error are filled by Batch.Do.
+type BatchCall struct {
+	Method string
+	Params interface{}
+	ID     int32
+
+	// Result holds the raw result returned for this call.
+	Result json.RawMessage
+	// Error is the API error returned for this call, if any.
+	Error *Error
+
+	dest interface{}
+	err  error
+}
+
+// Err returns the API error of the call, or the error met while decoding its result.
+func (c *BatchCall) Err() error {
+	if c.Error != nil {
+		return c.Error
+	}
+	return c.err
+}
+
+// MissingResponse use to generate error when a batch response lacks a call
+type MissingResponse int32
+
+func (e *MissingResponse) Error() string {
+	return fmt.Sprintf("No response for request id %d.", *e)
+}
+
+// NewBatch Creates an empty batch bound to api.
+func (api *API) NewBatch() *Batch {
+	return &Batch{api: api}
+}
+
+// Add Queues method with params.
+// If result is not nil the call result is unmarshalled into it when the batch is sent.
+func (b *Batch) Add(method string, params interface{}, result interface{}) *BatchCall {
+	c := &BatchCall{
+		Method: method,
+		Params: params,
+		ID:     atomic.AddInt32(&b.api.id, 1),
+		dest:   result,
+	}
+	b.calls = append(b.calls, c)
+	return c
+}
+
+// Calls Returns the queued calls in the order they were added.
+func (b *Batch) Calls() []*BatchCall {
+	return b.calls
+}
+
+// Len Returns the number of queued calls.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+// Endpoint Returns the url of the frontend that answered the last Do, see Config.Urls.
+func (b *Batch) Endpoint() string {
+	return b.endpoint
+}
+
+// Do Sends all queued calls in one POST.
+// The batch goes through Config.Middleware and Config.Transport as a single RPCRequest holding the calls.
+// err is something network or marshaling related. Caller should inspect each BatchCall to get API errors.
+func (b *Batch) Do() error {
+	return b.DoContext(context.Background())
+}
+
+// DoContext is like Do but uses ctx for the request.
+func (b *Batch) DoContext(ctx context.Context) (err error) {
+	if len(b.calls) == 0 {
+		return
+	}
+
+	if err = b.api.detectVersion(ctx); err != nil {
+		return
+	}
+	req := &RPCRequest{Auth: b.api.getAuth(), Batch: make([]*RPCRequest, len(b.calls))}
+	for i, c := range b.calls {
+		if err = b.api.checkMethod(c.Method); err != nil {
+			return
+		}
+		req.Batch[i] = &RPCRequest{Method: c.Method, Params: c.Params, ID: c.ID}
+	}
+
+	// a batch takes one slot and token of each limit applying to its calls
+	release, err := b.api.wait(ctx, req.Methods()...)
+	if err != nil {
+		return
+	}
+	defer release()
+
+	dones := make([]func(error), len(b.calls))
+	for i, c := range b.calls {
+		dones[i] = b.api.observe(c.Method)
+	}
+	defer func() {
+		for i, c := range b.calls {
+			switch {
+			case err != nil:
+				dones[i](err)
+			case c.Error != nil:
+				dones[i](c.Error)
+			default:
+				// decoding errors are not API errors
+				if _, missing := c.err.(*MissingResponse); missing {
+					dones[i](c.err)
+				} else {
+					dones[i](nil)
+				}
+			}
+		}
+	}()
+
+	res, err := b.api.doer().Do(ctx, req)
+	if err != nil {
+		return
+	}
+	b.endpoint = res.Endpoint
+	reportEndpoint(ctx, res.Endpoint)
+	// a malformed batch is answered with a single error object
+	if res.Error != nil {
+		res.Error.Endpoint = res.Endpoint
+		return res.Error
+	}
+
+	byID := make(map[int32]*RPCResponse, len(res.Batch))
+	for _, r := range res.Batch {
+		byID[r.ID] = r
+	}
+
+	for _, c := range b.calls {
+		r, ok := byID[c.ID]
+		if !ok {
+			e := MissingResponse(c.ID)
+			c.err = &e
+			continue
+		}
+		c.Result = r.Result
+		c.Error = r.Error
+		if c.Error != nil {
+			c.Error.Method, c.Error.RequestID, c.Error.Endpoint = c.Method, c.ID, res.Endpoint
+		}
+		if c.Error == nil && c.dest != nil {
+			c.err = json.Unmarshal(r.Result, c.dest)
+		}
+	}
+	return
+}
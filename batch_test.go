@@ -0,0 +1,133 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			ID     int32           `json:"id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			// version probe done by NewAPI
+			fmt.Fprint(w, `{"jsonrpc":"2.0","result":"5.0.0","id":1}`)
+			return
+		}
+		// answer in reverse order to check demultiplexing
+		out := []string{}
+		for i := len(reqs) - 1; i >= 0; i-- {
+			req := reqs[i]
+			switch req.Method {
+			case "host.get":
+				out = append(out, fmt.Sprintf(`{"jsonrpc":"2.0","result":[{"hostid":"1","host":"a"}],"id":%d}`, req.ID))
+			default:
+				out = append(out, fmt.Sprintf(`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params.","data":"nope"},"id":%d}`, req.ID))
+			}
+		}
+		fmt.Fprintf(w, "[%s", out[0])
+		for _, o := range out[1:] {
+			fmt.Fprintf(w, ",%s", o)
+		}
+		fmt.Fprint(w, "]")
+	}))
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hosts zapi.Hosts
+	b := api.NewBatch()
+	get := b.Add("host.get", zapi.Params{"output": "extend"}, &hosts)
+	bad := b.Add("item.get", zapi.Params{"bogus": 1}, nil)
+	if err := b.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	if get.Err() != nil {
+		t.Fatal(get.Err())
+	}
+	if len(hosts) != 1 || hosts[0].HostID != "1" {
+		t.Errorf("Bad hosts: %#v", hosts)
+	}
+	if bad.Error == nil || bad.Error.Code != -32602 {
+		t.Errorf("Expected code -32602, got %v", bad.Err())
+	}
+}
+
+func TestBatchTransport(t *testing.T) {
+	var sent, observed [][]string
+	call := func(req *zapi.RPCRequest) *zapi.RPCResponse {
+		switch req.Method {
+		case "APIInfo.version":
+			return &zapi.RPCResponse{ID: req.ID, Result: json.RawMessage(`"6.0.0"`)}
+		case "host.get":
+			return &zapi.RPCResponse{ID: req.ID, Result: json.RawMessage(`[{"hostid":"1","host":"a"}]`)}
+		case "item.get":
+			return &zapi.RPCResponse{ID: req.ID, Error: &zapi.Error{Code: -32602, Message: "Invalid params."}}
+		}
+		return nil
+	}
+	transport := zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+		sent = append(sent, req.Methods())
+		if !req.IsBatch() {
+			return call(req), nil
+		}
+		res := &zapi.RPCResponse{Endpoint: "http://zabbix.invalid"}
+		for _, c := range req.Batch {
+			if r := call(c); r != nil {
+				res.Batch = append(res.Batch, r)
+			}
+		}
+		return res, nil
+	})
+	observe := zapi.Observe(func(ctx context.Context, req *zapi.RPCRequest) (context.Context, func(*zapi.RPCResponse, error)) {
+		observed = append(observed, req.Methods())
+		return ctx, nil
+	})
+
+	api, err := zapi.NewAPI(zapi.Config{Url: "http://127.0.0.1:1/api_jsonrpc.php", Transport: transport, Middleware: []zapi.Middleware{observe}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hosts zapi.Hosts
+	b := api.NewBatch()
+	get := b.Add("host.get", zapi.Params{}, &hosts)
+	bad := b.Add("item.get", zapi.Params{"bogus": 1}, nil)
+	missing := b.Add("host.delete", []string{"1"}, nil)
+	if err = b.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if get.Err() != nil || len(hosts) != 1 || hosts[0].HostID != "1" {
+		t.Errorf("Bad hosts: %#v, %v", hosts, get.Err())
+	}
+	if bad.Error == nil || bad.Error.Method != "item.get" || bad.Error.RequestID != bad.ID || bad.Error.Endpoint != "http://zabbix.invalid" {
+		t.Errorf("Bad error: %#v", bad.Error)
+	}
+	var e *zapi.MissingResponse
+	if !errors.As(missing.Err(), &e) {
+		t.Errorf("Expected a MissingResponse, got %v", missing.Err())
+	}
+	if b.Endpoint() != "http://zabbix.invalid" {
+		t.Errorf("Bad endpoint %q", b.Endpoint())
+	}
+	// the batch is a single request through the middleware and the transport
+	expected := [][]string{{"APIInfo.version"}, {"host.get", "item.get", "host.delete"}}
+	if !reflect.DeepEqual(sent, expected) || !reflect.DeepEqual(observed, expected) {
+		t.Errorf("Expected %v through transport and middleware, got %v and %v", expected, sent, observed)
+	}
+}
@@ -0,0 +1,278 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Capability is an API feature only available in some Zabbix versions.
+// Versions are packed like Config.Version, 5.4.0 is 50400.
+type Capability struct {
+	Name string
+	// Since is the first version having the capability
+	Since int
+	// Until is the first version without the capability, 0 if it is still there
+	Until int
+}
+
+// Capabilities checked by this package
+var (
+	CapItemDataType         = Capability{Name: "item data_type and delta", Until: 30400}
+	CapApplications         = Capability{Name: "applications", Until: 50400}
+	CapHostAvailable        = Capability{Name: "host availability", Until: 50400}
+	CapOldTriggerExpression = Capability{Name: "{host:key.func()} trigger expressions", Until: 50400}
+	CapNewTriggerExpression = Capability{Name: "func(/host/key) trigger expressions", Since: 50400}
+	CapLoginUsername        = Capability{Name: "user.login username parameter", Since: 50400}
+	CapAPITokens            = Capability{Name: "API tokens", Since: 50400}
+	CapBearerAuth           = Capability{Name: "Authorization header", Since: 60400}
+	CapUnacknowledge        = Capability{Name: "event unacknowledgement", Since: 60000}
+	CapEventSuppress        = Capability{Name: "problem suppression", Since: 60400}
+	CapHistoryPush          = Capability{Name: "history.push", Since: 70000}
+)
+
+// SupportedBy reports whether version has the capability.
+func (c Capability) SupportedBy(version int) bool {
+	return version >= c.Since && (c.Until == 0 || version < c.Until)
+}
+
+// Supports reports whether the connected server has c.
+// A version not detected yet, see Config.LazyVersion, is taken as the oldest one.
+func (api *API) Supports(c Capability) bool {
+	return c.SupportedBy(api.version())
+}
+
+// ErrUnsupported is matched through errors.Is by UnsupportedError
+var ErrUnsupported = errors.New("zabbix: not supported by this version")
+
+// UnsupportedError is returned before sending a call using a capability the connected server lacks.
+type UnsupportedError struct {
+	Capability Capability
+	Version    int
+	// Usage is what needed the capability, such as a method or a field
+	Usage string
+}
+
+func (e *UnsupportedError) Error() string {
+	var since string
+	switch {
+	case e.Capability.Until != 0:
+		since = fmt.Sprintf("removed in %s", semVerOf(e.Capability.Until))
+	default:
+		since = fmt.Sprintf("added in %s", semVerOf(e.Capability.Since))
+	}
+	return fmt.Sprintf("%s: %s not supported by Zabbix %s (%s)", e.Usage, e.Capability.Name, semVerOf(e.Version), since)
+}
+
+// Is matches ErrUnsupported.
+func (e *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// require returns an UnsupportedError if the connected server lacks c.
+// Nothing is checked while the version is unknown.
+func (api *API) require(c Capability, usage string) error {
+	if v := api.version(); v != 0 && !c.SupportedBy(v) {
+		return &UnsupportedError{c, v, usage}
+	}
+	return nil
+}
+
+// methodCapabilities lists the API objects whose methods need a capability, and single methods needing one
+var methodCapabilities = map[string]Capability{
+	"application":  CapApplications,
+	"token":        CapAPITokens,
+	"history.push": CapHistoryPush,
+}
+
+// checkMethod fails calls to methods the connected server lacks
+func (api *API) checkMethod(method string) error {
+	object := strings.ToLower(method)
+	if c, ok := methodCapabilities[object]; ok {
+		return api.require(c, method)
+	}
+	if i := strings.IndexByte(object, '.'); i >= 0 {
+		object = object[:i]
+	}
+	if c, ok := methodCapabilities[object]; ok {
+		return api.require(c, method)
+	}
+	return nil
+}
+
+// payloadRule adapts objects sent to create and update methods of a server lacking a capability.
+// adapt reports uses it cannot adapt through unsupported.
+type payloadRule struct {
+	objects []string
+	lacking Capability
+	adapt   func(o map[string]json.RawMessage, unsupported func(usage string) error) error
+}
+
+// payloadRules is the table consulted by adaptPayload
+var payloadRules = []payloadRule{
+	{[]string{"item", "itemprototype"}, CapItemDataType, dropDefault("data_type", "delta")},
+	{[]string{"item", "itemprototype"}, CapApplications, dropDefault("applications")},
+	// read only, reported by interfaces instead
+	{[]string{"host"}, CapHostAvailable, drop("available")},
+	{[]string{"trigger", "triggerprototype"}, CapOldTriggerExpression, convertExpressions},
+	{[]string{"trigger", "triggerprototype"}, CapNewTriggerExpression, rejectNewExpressions},
+}
+
+// adaptPayload applies payloadRules to objects sent to object.create or object.update.
+// objects are returned unchanged when no rule applies or the version is unknown.
+func (api *API) adaptPayload(object string, objects interface{}) (interface{}, error) {
+	v := api.version()
+	if v == 0 {
+		return objects, nil
+	}
+	var rules []payloadRule
+	for _, r := range payloadRules {
+		if !r.lacking.SupportedBy(v) && containsString(r.objects, object) {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return objects, nil
+	}
+
+	b, err := json.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+	var res []map[string]json.RawMessage
+	if err = json.Unmarshal(b, &res); err != nil {
+		return nil, err
+	}
+	for _, o := range res {
+		for _, r := range rules {
+			unsupported := func(usage string) error {
+				return &UnsupportedError{r.lacking, v, object + "." + usage}
+			}
+			if err = r.adapt(o, unsupported); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+// drop removes fields whatever their value
+func drop(fields ...string) func(map[string]json.RawMessage, func(string) error) error {
+	return func(o map[string]json.RawMessage, unsupported func(string) error) error {
+		for _, f := range fields {
+			delete(o, f)
+		}
+		return nil
+	}
+}
+
+// isDefault reports whether raw holds a zero or empty value
+func isDefault(raw json.RawMessage) bool {
+	switch strings.TrimSpace(string(raw)) {
+	case "", "null", `""`, `"0"`, "0", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// dropDefault removes fields left to their zero value and rejects fields set by the caller
+func dropDefault(fields ...string) func(map[string]json.RawMessage, func(string) error) error {
+	return func(o map[string]json.RawMessage, unsupported func(string) error) error {
+		for _, f := range fields {
+			raw, ok := o[f]
+			if !ok {
+				continue
+			}
+			if !isDefault(raw) {
+				return unsupported(f)
+			}
+			delete(o, f)
+		}
+		return nil
+	}
+}
+
+// expressionFields are the trigger fields holding an expression
+var expressionFields = []string{"expression", "recovery_expression"}
+
+// oldFunction matches a function of the pre 5.4 syntax, {host:key.func(params)}
+var oldFunction = regexp.MustCompile(`\{([^:{}]+):(.+?)\.(\w+)\(([^()]*)\)\}`)
+
+// newFunction matches a function of the 5.4 syntax, func(/host/key,params)
+var newFunction = regexp.MustCompile(`\w+\(/[^/]*/`)
+
+// convertible lists the functions whose meaning did not change with the 5.4 syntax,
+// as long as they have at most one parameter
+var convertible = map[string]bool{
+	"last": true, "avg": true, "min": true, "max": true, "sum": true, "nodata": true, "change": true,
+}
+
+// convertExpressions rewrites {host:key.func(param)} functions to func(/host/key,param).
+// Functions whose parameters or meaning changed are rejected.
+func convertExpressions(o map[string]json.RawMessage, unsupported func(string) error) error {
+	for _, f := range expressionFields {
+		raw, ok := o[f]
+		if !ok {
+			continue
+		}
+		var expr string
+		if err := json.Unmarshal(raw, &expr); err != nil {
+			return err
+		}
+		var failed error
+		expr = oldFunction.ReplaceAllStringFunc(expr, func(m string) string {
+			parts := oldFunction.FindStringSubmatch(m)
+			host, key, fn, param := parts[1], parts[2], parts[3], strings.TrimSpace(parts[4])
+			if !convertible[fn] || strings.Contains(param, ",") {
+				if failed == nil {
+					failed = unsupported(f + " " + m)
+				}
+				return m
+			}
+			// last ignored its parameter unless it was a count of values
+			if fn == "last" && !strings.HasPrefix(param, "#") {
+				param = ""
+			}
+			if param == "" {
+				return fmt.Sprintf("%s(/%s/%s)", fn, host, key)
+			}
+			return fmt.Sprintf("%s(/%s/%s,%s)", fn, host, key, param)
+		})
+		if failed != nil {
+			return failed
+		}
+		b, err := json.Marshal(expr)
+		if err != nil {
+			return err
+		}
+		o[f] = b
+	}
+	return nil
+}
+
+// rejectNewExpressions fails on expressions using the 5.4 syntax
+func rejectNewExpressions(o map[string]json.RawMessage, unsupported func(string) error) error {
+	for _, f := range expressionFields {
+		var expr string
+		if raw, ok := o[f]; ok {
+			if err := json.Unmarshal(raw, &expr); err != nil {
+				return err
+			}
+		}
+		if m := newFunction.FindString(expr); m != "" {
+			return unsupported(f + " " + m)
+		}
+	}
+	return nil
+}
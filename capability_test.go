@@ -0,0 +1,220 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestCapabilitySupportedBy(t *testing.T) {
+	for _, c := range []struct {
+		cap     zapi.Capability
+		version int
+		exp     bool
+	}{
+		{zapi.CapItemDataType, 30200, true},
+		{zapi.CapItemDataType, 30400, false},
+		{zapi.CapApplications, 50000, true},
+		{zapi.CapApplications, 50400, false},
+		{zapi.CapAPITokens, 50000, false},
+		{zapi.CapAPITokens, 60000, true},
+		{zapi.CapBearerAuth, 60200, false},
+		{zapi.CapBearerAuth, 70000, true},
+	} {
+		if got := c.cap.SupportedBy(c.version); got != c.exp {
+			t.Errorf("%s by %d: expected %v, got %v", c.cap.Name, c.version, c.exp, got)
+		}
+	}
+}
+
+// capturingAPI connects to a server of version recording the params of each call but apiinfo.version
+func capturingAPI(t *testing.T, version string) (*zapi.API, map[string]json.RawMessage) {
+	url, sent := capturingServer(t, version)
+	api, err := zapi.NewAPI(zapi.Config{Url: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, sent
+}
+
+// capturingServer is the server of capturingAPI
+func capturingServer(t *testing.T, version string) (string, map[string]json.RawMessage) {
+	sent := map[string]json.RawMessage{}
+	srv := newRPCServer(t, version, func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		sent[method] = params
+		object := method[:strings.IndexByte(method, '.')]
+		var objects []map[string]interface{}
+		json.Unmarshal(params, &objects)
+		ids := make([]string, len(objects))
+		for i := range ids {
+			ids[i] = "1"
+		}
+		return map[string]interface{}{object + "ids": ids}, nil
+	})
+	t.Cleanup(srv.Close)
+	return srv.URL, sent
+}
+
+func TestAdaptTriggerExpressions(t *testing.T) {
+	api, sent := capturingAPI(t, "5.4.0")
+
+	triggers := zapi.Triggers{{
+		Description:        "load",
+		Expression:         "{web:system.cpu.load[all,avg1].avg(5m)}>2 and {web:agent.ping.last(0)}=0",
+		RecoveryExpression: "{web:system.cpu.load[all,avg1].last(#3)}<1",
+	}}
+	if err := api.TriggersCreate(triggers); err != nil {
+		t.Fatal(err)
+	}
+	var got []zapi.Trigger
+	if err := json.Unmarshal(sent["trigger.create"], &got); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "avg(/web/system.cpu.load[all,avg1],5m)>2 and last(/web/agent.ping)=0"; got[0].Expression != exp {
+		t.Errorf("Expected %q, got %q", exp, got[0].Expression)
+	}
+	if exp := "last(/web/system.cpu.load[all,avg1],#3)<1"; got[0].RecoveryExpression != exp {
+		t.Errorf("Expected %q, got %q", exp, got[0].RecoveryExpression)
+	}
+	if triggers[0].Expression != "{web:system.cpu.load[all,avg1].avg(5m)}>2 and {web:agent.ping.last(0)}=0" {
+		t.Errorf("Caller trigger modified: %q", triggers[0].Expression)
+	}
+
+	// diff has no equivalent
+	delete(sent, "trigger.create")
+	err := api.TriggersCreate(zapi.Triggers{{Description: "diff", Expression: "{web:agent.version.diff(0)}=1"}})
+	if !errors.Is(err, zapi.ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "diff(0)") || !strings.Contains(err.Error(), "5.4.0") {
+		t.Errorf("Unclear error: %s", err)
+	}
+	if _, ok := sent["trigger.create"]; ok {
+		t.Error("Unsupported trigger was sent")
+	}
+
+	api, sent = capturingAPI(t, "5.0.0")
+	err = api.TriggersCreate(zapi.Triggers{{Description: "new", Expression: "last(/web/agent.ping)=0"}})
+	var unsupported *zapi.UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Capability != zapi.CapNewTriggerExpression {
+		t.Fatalf("Expected an UnsupportedError, got %v", err)
+	}
+	old := zapi.Triggers{{Description: "old", Expression: "{web:agent.ping.last(0)}=0"}}
+	if err = api.TriggersCreate(old); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sent["trigger.create"]), `"{web:agent.ping.last(0)}=0"`) {
+		t.Errorf("Expression changed: %s", sent["trigger.create"])
+	}
+}
+
+func TestAdaptItems(t *testing.T) {
+	api, sent := capturingAPI(t, "5.4.0")
+
+	items := zapi.Items{{HostID: "1", Key: "agent.ping", Name: "ping"}}
+	if err := api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(sent["item.create"], &got); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"data_type", "delta", "applications"} {
+		if _, ok := got[0][f]; ok {
+			t.Errorf("%s sent: %v", f, got[0])
+		}
+	}
+	if items[0].ItemID != "1" {
+		t.Errorf("Item id not set: %#v", items[0])
+	}
+
+	items = zapi.Items{{HostID: "1", Key: "agent.ping", Name: "ping", Applications: []string{"2"}}}
+	err := api.ItemsCreate(items)
+	var unsupported *zapi.UnsupportedError
+	if !errors.As(err, &unsupported) || unsupported.Capability != zapi.CapApplications {
+		t.Fatalf("Expected an UnsupportedError, got %v", err)
+	}
+
+	api, sent = capturingAPI(t, "5.0.0")
+	if err = api.ItemsUpdate(items); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(sent["item.update"]), `"applications":["2"]`) {
+		t.Errorf("Applications not sent: %s", sent["item.update"])
+	}
+}
+
+func TestAdaptHosts(t *testing.T) {
+	for version, exp := range map[string]bool{"5.0.0": true, "5.4.0": false} {
+		api, sent := capturingAPI(t, version)
+		if err := api.HostsCreate(zapi.Hosts{{Host: "web"}}); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(sent["host.create"]), `"available"`); got != exp {
+			t.Errorf("%s: expected available sent %v: %s", version, exp, sent["host.create"])
+		}
+	}
+}
+
+func TestUnsupportedMethods(t *testing.T) {
+	api, sent := capturingAPI(t, "5.0.0")
+	if _, err := api.TokensGet(zapi.Params{}); !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("Unsupported calls sent: %v", sent)
+	}
+
+	api, sent = capturingAPI(t, "6.0.0")
+	if _, err := api.ApplicationsGet(zapi.Params{}); !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	b := api.NewBatch()
+	b.Add("host.get", zapi.Params{}, nil)
+	b.Add("application.get", zapi.Params{}, nil)
+	if err := b.Do(); !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if len(sent) != 0 {
+		t.Errorf("Unsupported calls sent: %v", sent)
+	}
+	if !api.Supports(zapi.CapAPITokens) || api.Supports(zapi.CapApplications) {
+		t.Error("Bad Supports for 6.0.0")
+	}
+}
+
+func TestAdaptLazyVersion(t *testing.T) {
+	url, sent := capturingServer(t, "6.0.0")
+	api, err := zapi.NewAPI(zapi.Config{Url: url, LazyVersion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first call detects the version before adapting its payload
+	if err = api.TriggersCreate(zapi.Triggers{{Description: "ping", Expression: "{h:k.last()}=0"}}); err != nil {
+		t.Fatal(err)
+	}
+	var got []zapi.Trigger
+	if err = json.Unmarshal(sent["trigger.create"], &got); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "last(/h/k)=0"; got[0].Expression != exp {
+		t.Errorf("Expected %q, got %q", exp, got[0].Expression)
+	}
+
+	api, err = zapi.NewAPI(zapi.Config{Url: url, LazyVersion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = api.EventsAcknowledge(zapi.EventAcknowledgement{EventIDs: []string{"42"}, Action: zapi.AckSuppress})
+	if !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, ok := sent["event.acknowledge"]; ok {
+		t.Error("Unsupported acknowledge sent")
+	}
+}
@@ -0,0 +1,241 @@
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+)
+
+// Interaction is a recorded call and its outcome.
+type Interaction struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+	// TransportError is the message of a network or marshaling error
+	TransportError string `json:"transport_error,omitempty"`
+	// Batch holds the calls of a batch, Method and Params are empty for a batch.
+	// Error is set instead of the results of the calls when the whole batch was rejected.
+	Batch []Interaction `json:"batch,omitempty"`
+}
+
+// Cassette records calls going through the middleware chain and replays them later without a server.
+// Secrets such as auth, passwords and tokens are redacted before being recorded.
+//
+// To record, add Record() to Config.Middleware and Save the cassette once done.
+// To replay, use a loaded cassette as Config.Transport: calls must be made in the recorded order,
+// with the same method and params.
+// A batch is recorded as a single interaction holding its calls, and replayed when sent with the same calls.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+
+	mu   sync.Mutex
+	next int
+}
+
+// CassetteMismatch is returned when a replayed call differs from the recorded one.
+type CassetteMismatch struct {
+	Index    int
+	Expected string
+	Got      string
+}
+
+func (e *CassetteMismatch) Error() string {
+	return fmt.Sprintf("Cassette interaction %d: expected %s, got %s.", e.Index, e.Expected, e.Got)
+}
+
+// errCassetteExhausted is returned when more calls are replayed than recorded
+var errCassetteExhausted = errors.New("cassette has no more interactions")
+
+// NewCassette Creates an empty cassette.
+func NewCassette() *Cassette {
+	return &Cassette{}
+}
+
+// LoadCassette Reads a cassette saved by Save.
+func LoadCassette(path string) (c *Cassette, err error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	c = &Cassette{}
+	err = json.Unmarshal(b, c)
+	return
+}
+
+// Save Writes the recorded interactions to path.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	b, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Record returns a middleware appending each call to the cassette.
+func (c *Cassette) Record() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *RPCRequest) (res *RPCResponse, err error) {
+			i, perr := recordRequest(req)
+			if perr != nil {
+				return nil, perr
+			}
+			res, err = next.Do(ctx, req)
+
+			switch {
+			case err != nil:
+				i.TransportError = err.Error()
+			case res.Error != nil:
+				e := *res.Error
+				i.Error = &e
+			case req.IsBatch():
+				byID := make(map[int32]*RPCResponse, len(res.Batch))
+				for _, r := range res.Batch {
+					byID[r.ID] = r
+				}
+				for j, call := range req.Batch {
+					if r, ok := byID[call.ID]; ok {
+						recordResponse(&i.Batch[j], call, r)
+					}
+				}
+			default:
+				recordResponse(&i, req, res)
+			}
+
+			c.mu.Lock()
+			c.Interactions = append(c.Interactions, i)
+			c.mu.Unlock()
+			return
+		})
+	}
+}
+
+// recordRequest returns the interaction of req, without its outcome
+func recordRequest(req *RPCRequest) (i Interaction, err error) {
+	if !req.IsBatch() {
+		i.Method = req.Method
+		i.Params, err = recordParams(req.Params)
+		return
+	}
+	i.Batch = make([]Interaction, len(req.Batch))
+	for j, call := range req.Batch {
+		if i.Batch[j], err = recordRequest(call); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// recordResponse sets the outcome of the call req to the API response res
+func recordResponse(i *Interaction, req *RPCRequest, res *RPCResponse) {
+	switch {
+	case res.Error != nil:
+		e := *res.Error
+		i.Error = &e
+	case strings.EqualFold(req.Method, "user.login"):
+		i.Result, _ = json.Marshal(redacted)
+	default:
+		i.Result = redactJSON(res.Result)
+	}
+}
+
+// Do Replays the next recorded interaction, making the cassette usable as Config.Transport.
+func (c *Cassette) Do(ctx context.Context, req *RPCRequest) (*RPCResponse, error) {
+	got, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.next >= len(c.Interactions) {
+		return nil, errCassetteExhausted
+	}
+	i := c.Interactions[c.next]
+	if !sameRequest(i, got) {
+		return nil, &CassetteMismatch{c.next, describe(i), describe(got)}
+	}
+	c.next++
+
+	if i.TransportError != "" {
+		return nil, errors.New(i.TransportError)
+	}
+	if i.Error != nil || !req.IsBatch() {
+		return replay(i, req.ID), nil
+	}
+	res := &RPCResponse{Batch: []*RPCResponse{}}
+	for j, call := range req.Batch {
+		// calls left unanswered by the server were recorded without an outcome
+		if i.Batch[j].Result != nil || i.Batch[j].Error != nil {
+			res.Batch = append(res.Batch, replay(i.Batch[j], call.ID))
+		}
+	}
+	return res, nil
+}
+
+// replay returns the recorded response of a call, with the id of the replayed request
+func replay(i Interaction, id int32) *RPCResponse {
+	res := &RPCResponse{Result: i.Result, ID: id}
+	if i.Error != nil {
+		e := *i.Error
+		res.Error = &e
+	}
+	return res
+}
+
+// sameRequest reports whether got replays the request of the recorded interaction i
+func sameRequest(i, got Interaction) bool {
+	if i.Method != got.Method || !sameJSON(i.Params, got.Params) || len(i.Batch) != len(got.Batch) {
+		return false
+	}
+	for j := range i.Batch {
+		if !sameRequest(i.Batch[j], got.Batch[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns the request of an interaction for CassetteMismatch
+func describe(i Interaction) string {
+	if i.Batch == nil {
+		return fmt.Sprintf("%s %s", i.Method, i.Params)
+	}
+	calls := make([]string, len(i.Batch))
+	for j := range i.Batch {
+		calls[j] = describe(i.Batch[j])
+	}
+	return fmt.Sprintf("batch [%s]", strings.Join(calls, ", "))
+}
+
+// Remaining Returns the number of recorded interactions not replayed yet.
+func (c *Cassette) Remaining() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Interactions) - c.next
+}
+
+// recordParams encodes params the way they are recorded
+func recordParams(params interface{}) (json.RawMessage, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return redactJSON(b), nil
+}
+
+// sameJSON compares encoded values regardless of indentation
+func sameJSON(a, b []byte) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
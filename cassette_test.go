@@ -0,0 +1,154 @@
+package zabbix_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+func TestCassette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	// record
+	srv := zabbixtest.NewServer(zabbixtest.Config{Version: "5.4.0"})
+	cassette := zapi.NewCassette()
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Middleware: []zapi.Middleware{cassette.Record()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := zapi.HostGroups{{Name: "recorded"}}
+	if err = api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := api.HostGroupsGet(zapi.Params{"filter": map[string]string{"name": "recorded"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := zapi.Tokens{{Name: "recorded"}}
+	if err = api.TokensCreate(tokens); err != nil {
+		t.Fatal(err)
+	}
+	generated, err := api.TokensGenerate([]string{tokens[0].TokenID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.HostGroupsCreate(zapi.HostGroups{{Name: "recorded"}}); !errors.Is(err, zapi.ErrAlreadyExists) {
+		t.Fatalf("Expected already exists, got %v", err)
+	}
+	srv.Close()
+
+	if err = cassette.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{auth, zabbixtest.DefaultPassword, generated[0].Token} {
+		if strings.Contains(string(b), secret) {
+			t.Errorf("Secret %q recorded", secret)
+		}
+	}
+
+	// replay without server
+	cassette, err = zapi.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err = zapi.NewAPI(zapi.Config{Transport: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.Config.Version != 50400 {
+		t.Errorf("Expected replayed version 50400, got %d", api.Config.Version)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, "another password"); err != nil {
+		t.Fatal(err)
+	}
+	replayedGroups := zapi.HostGroups{{Name: "recorded"}}
+	if err = api.HostGroupsCreate(replayedGroups); err != nil {
+		t.Fatal(err)
+	}
+	if replayedGroups[0].GroupID != groups[0].GroupID {
+		t.Errorf("Expected group id %s, got %s", groups[0].GroupID, replayedGroups[0].GroupID)
+	}
+	replayed, err := api.HostGroupsGet(zapi.Params{"filter": map[string]string{"name": "recorded"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Expected %#v, got %#v", recorded, replayed)
+	}
+
+	// out of order call
+	_, err = api.HostsGet(zapi.Params{})
+	var mismatch *zapi.CassetteMismatch
+	if !errors.As(err, &mismatch) || mismatch.Index != 4 {
+		t.Fatalf("Expected mismatch at 4, got %v", err)
+	}
+	if cassette.Remaining() != 3 {
+		t.Errorf("Expected 3 remaining interactions, got %d", cassette.Remaining())
+	}
+}
+
+func TestCassetteBatch(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{Version: "6.0.0"})
+	defer srv.Close()
+	cassette := zapi.NewCassette()
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Middleware: []zapi.Middleware{cassette.Record()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	batch := func(api *zapi.API) (groups zapi.HostGroups, dup *zapi.BatchCall) {
+		b := api.NewBatch()
+		b.Add("hostgroup.create", zapi.HostGroups{{Name: "batched"}}, nil)
+		dup = b.Add("hostgroup.create", zapi.HostGroups{{Name: "batched"}}, nil)
+		b.Add("hostgroup.get", zapi.Params{"filter": map[string]string{"name": "batched"}}, &groups)
+		if err := b.Do(); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	recorded, _ := batch(api)
+	if len(cassette.Interactions) != 3 || len(cassette.Interactions[2].Batch) != 3 {
+		t.Fatalf("Expected the batch recorded as one interaction, got %#v", cassette.Interactions)
+	}
+
+	// replay
+	api, err = zapi.NewAPI(zapi.Config{Transport: cassette})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	replayed, dup := batch(api)
+	if len(replayed) != 1 || !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("Expected %#v, got %#v", recorded, replayed)
+	}
+	if !errors.Is(dup.Err(), zapi.ErrAlreadyExists) {
+		t.Errorf("Expected replayed already exists, got %v", dup.Err())
+	}
+	if cassette.Remaining() != 0 {
+		t.Errorf("Expected no remaining interactions, got %d", cassette.Remaining())
+	}
+}
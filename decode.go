@@ -0,0 +1,48 @@
+package zabbix
+
+import "fmt"
+
+// DecodeError is returned when a field of an object returned by the API
+// does not have the expected shape.
+type DecodeError struct {
+	// Object is the API object type, such as "host" or "item"
+	Object string
+	// ID is the id of the offending object
+	ID string
+	// Field is the JSON field that could not be decoded
+	Field string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("cannot decode field %q of %s: %s", e.Field, e.Object, e.Err)
+	}
+	return fmt.Sprintf("cannot decode field %q of %s %s: %s", e.Field, e.Object, e.ID, e.Err)
+}
+
+// Unwrap returns the underlying decoding error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DuplicateKeyError is returned by Items.ByKey when two items share a key
+type DuplicateKeyError string
+
+func (e DuplicateKeyError) Error() string {
+	return fmt.Sprintf("Duplicate key %s", string(e))
+}
+
+// decodeFailed handles an object that could not be decoded.
+// Unless Config.Lenient is set the error is returned and the call fails,
+// in lenient mode it is passed to Config.OnDecodeError and nil is returned so the object can be skipped.
+func (api *API) decodeFailed(e *DecodeError) error {
+	api.printf("got error during unmarshal %s", e)
+	if !api.Config.Lenient {
+		return e
+	}
+	if api.Config.OnDecodeError != nil {
+		api.Config.OnDecodeError(e)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestDecodeError(t *testing.T) {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		return []map[string]interface{}{
+			{"hostid": "1", "host": "good", "inventory": map[string]string{"os": "linux"}},
+			{"hostid": "2", "host": "bad", "inventory": "oops"},
+		}, nil
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = api.HostsGet(zapi.Params{})
+	e, ok := err.(*zapi.DecodeError)
+	if !ok {
+		t.Fatalf("Expected a decode error, got %#v", err)
+	}
+	if e.ID != "2" || e.Field != "inventory" {
+		t.Errorf("Bad decode error: %s", e)
+	}
+
+	var skipped []*zapi.DecodeError
+	api.Config.Lenient = true
+	api.Config.OnDecodeError = func(e *zapi.DecodeError) {
+		skipped = append(skipped, e)
+	}
+	hosts, err := api.HostsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Inventory["os"] != "linux" {
+		t.Errorf("Bad hosts: %#v", hosts)
+	}
+	if len(skipped) != 1 || skipped[0].ID != "2" {
+		t.Errorf("Bad skipped hosts: %v", skipped)
+	}
+}
+
+func TestItemsByKey(t *testing.T) {
+	items := zapi.Items{{Key: "a"}, {Key: "b"}}
+	m, err := items.ByKey()
+	if err != nil || len(m) != 2 {
+		t.Fatalf("Bad map %v: %v", m, err)
+	}
+
+	items = append(items, zapi.Item{Key: "a"})
+	if _, err = items.ByKey(); err != zapi.DuplicateKeyError("a") {
+		t.Errorf("Expected duplicate key error, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// defaultEndpointCooldown is how long a failed endpoint is avoided if Config.EndpointCooldown is zero
+const defaultEndpointCooldown = 30 * time.Second
+
+// endpoint is one of the frontends of Config.Url and Config.Urls
+type endpoint struct {
+	index int
+	url   string
+
+	mu        sync.Mutex
+	downUntil time.Time
+	lastErr   error
+}
+
+// cooling reports whether the endpoint failed recently and should be avoided
+func (e *endpoint) cooling(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return now.Before(e.downUntil)
+}
+
+// recovering reports whether the endpoint failed and its cooldown is over
+func (e *endpoint) recovering(now time.Time) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.lastErr != nil && !now.Before(e.downUntil)
+}
+
+func (e *endpoint) markDown(err error, cooldown time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil, e.lastErr = time.Now().Add(cooldown), err
+}
+
+func (e *endpoint) markUp() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.downUntil, e.lastErr = time.Time{}, nil
+}
+
+// newEndpoints lists Config.Url then Config.Urls, without duplicates
+func newEndpoints(c Config) (res []*endpoint) {
+	seen := map[string]bool{}
+	for _, u := range append([]string{c.Url}, c.Urls...) {
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		res = append(res, &endpoint{index: len(res), url: u})
+	}
+	if len(res) == 0 {
+		// requests fail with the error of the HTTP client
+		res = append(res, &endpoint{})
+	}
+	return
+}
+
+func (api *API) endpointCooldown() time.Duration {
+	if api.Config.EndpointCooldown > 0 {
+		return api.Config.EndpointCooldown
+	}
+	return defaultEndpointCooldown
+}
+
+// canFailover reports whether a call that failed with the transport error or 5xx answer err may be sent to another endpoint.
+// Calls that are not idempotent only fail over if the connection could not be established,
+// as the failed frontend may have executed them.
+func canFailover(err error, idempotent bool) bool {
+	if idempotent {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// postAny posts body to the preferred endpoint, failing over to the others on transport errors and 5xx answers.
+// Endpoints that failed recently are tried last, and are health checked before use once their cooldown is over.
+// The session or token is the same for all endpoints, they share the Zabbix database.
+func (api *API) postAny(ctx context.Context, idempotent bool, body []byte, bearer string) (b []byte, url string, err error) {
+	if len(api.endpoints) == 1 {
+		url = api.endpoints[0].url
+		b, err = api.post(ctx, url, body, bearer)
+		return
+	}
+
+	now := time.Now()
+	first := int(api.preferred.Load())
+	order := make([]*endpoint, 0, len(api.endpoints))
+	var cooling []*endpoint
+	for i := range api.endpoints {
+		e := api.endpoints[(first+i)%len(api.endpoints)]
+		if e.cooling(now) {
+			cooling = append(cooling, e)
+		} else {
+			order = append(order, e)
+		}
+	}
+	// better try failed endpoints than nothing
+	order = append(order, cooling...)
+
+	for i, e := range order {
+		if i > 0 {
+			api.printf("Failing over to %s after: %s", e.url, err)
+		}
+		if e.recovering(now) && i < len(order)-1 {
+			if s := api.checkEndpoint(ctx, e); e.cooling(time.Now()) {
+				err = s.Err
+				continue
+			}
+		}
+
+		url = e.url
+		b, err = api.post(ctx, url, body, bearer)
+		var httpErr *HTTPError
+		if err == nil || errors.As(err, &httpErr) && httpErr.StatusCode < 500 {
+			// the frontend is up and answered
+			e.markUp()
+			api.preferred.Store(int32(e.index))
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		e.markDown(err, api.endpointCooldown())
+		if !canFailover(err, idempotent) {
+			return
+		}
+	}
+	return
+}
+
+// endpointReportKey is the context key of WithEndpointReport
+type endpointReportKey struct{}
+
+// WithEndpointReport returns a context making calls report the url of the frontend that answered them,
+// including successful calls of typed wrappers such as HostsGet. See Config.Urls.
+func WithEndpointReport(ctx context.Context, report func(url string)) context.Context {
+	return context.WithValue(ctx, endpointReportKey{}, report)
+}
+
+// reportEndpoint calls the function of WithEndpointReport, if any
+func reportEndpoint(ctx context.Context, url string) {
+	if report, ok := ctx.Value(endpointReportKey{}).(func(string)); ok && url != "" {
+		report(url)
+	}
+}
+
+// EndpointStatus is the health of a frontend, see CheckEndpoints.
+type EndpointStatus struct {
+	URL string
+	// Version is the answer to apiinfo.version
+	Version string
+	Latency time.Duration
+	Err     error
+}
+
+// CheckEndpoints Calls "APIInfo.version" on each frontend of Config.Url and Config.Urls.
+// Frontends that cannot be reached are avoided by the following calls, the others are used again.
+// A frontend answering with an API error, such as Zabbix 2.2 requiring auth for this method, is healthy.
+func (api *API) CheckEndpoints(ctx context.Context) []EndpointStatus {
+	res := make([]EndpointStatus, len(api.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range api.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			res[i] = api.checkEndpoint(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+	return res
+}
+
+// checkEndpoint calls apiinfo.version on e and records its health
+func (api *API) checkEndpoint(ctx context.Context, e *endpoint) (s EndpointStatus) {
+	s.URL = e.url
+	body, err := json.Marshal(request{"2.0", "apiinfo.version", Params{}, "", 0})
+	if err != nil {
+		s.Err = err
+		return
+	}
+
+	start := time.Now()
+	b, err := api.post(ctx, e.url, body, "")
+	s.Latency = time.Since(start)
+	var raw RawResponse
+	if err == nil {
+		err = json.Unmarshal(b, &raw)
+	}
+	if err != nil {
+		s.Err = err
+		e.markDown(err, api.endpointCooldown())
+		return
+	}
+
+	e.markUp()
+	if raw.Error != nil {
+		s.Err = raw.Error
+	} else {
+		s.Err = json.Unmarshal(raw.Result, &s.Version)
+	}
+	return
+}
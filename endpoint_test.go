@@ -0,0 +1,221 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+// brokenServer accepts connections and closes them without answering, counting requests
+func brokenServer(t *testing.T) (*httptest.Server, func() int) {
+	var mu sync.Mutex
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return hits
+	}
+}
+
+// deadURL returns the url of a server that was shut down, refusing connections
+func deadURL() string {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	return srv.URL
+}
+
+// tokenServer requires auth to be token
+func tokenServer(t *testing.T, token string) *httptest.Server {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		if auth != token {
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Not authorised."}
+		}
+		if method == "host.create" {
+			return map[string][]string{"hostids": {"1"}}, nil
+		}
+		if method == "host.delete" {
+			return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "No permissions."}
+		}
+		return []interface{}{}, nil
+	})
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// endpointRecorder is a middleware recording the endpoint of each call
+type endpointRecorder struct {
+	mu        sync.Mutex
+	endpoints []string
+}
+
+func (r *endpointRecorder) middleware(next zapi.Doer) zapi.Doer {
+	return zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+		res, err := next.Do(ctx, req)
+		if err == nil {
+			r.mu.Lock()
+			r.endpoints = append(r.endpoints, res.Endpoint)
+			r.mu.Unlock()
+		}
+		return res, err
+	})
+}
+
+func (r *endpointRecorder) last() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.endpoints[len(r.endpoints)-1]
+}
+
+func TestFailover(t *testing.T) {
+	broken, hits := brokenServer(t)
+	live := tokenServer(t, "secret")
+
+	rec := &endpointRecorder{}
+	api, err := zapi.NewAPI(zapi.Config{
+		Url:        deadURL(),
+		Urls:       []string{broken.URL, live.URL},
+		Token:      "secret",
+		Middleware: []zapi.Middleware{rec.middleware},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec.last() != live.URL {
+		t.Errorf("Version served by %q", rec.last())
+	}
+	if hits() != 1 {
+		t.Errorf("Expected 1 request to the broken endpoint, got %d", hits())
+	}
+
+	// the live endpoint is now preferred, failed ones are not tried again
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if rec.last() != live.URL || hits() != 1 {
+		t.Errorf("Served by %q after %d broken requests", rec.last(), hits())
+	}
+
+	err = api.HostsDeleteByIds([]string{"1"})
+	var e *zapi.Error
+	if !errors.As(err, &e) || e.Endpoint != live.URL {
+		t.Errorf("Expected an API error from %s, got %#v", live.URL, err)
+	}
+}
+
+func TestFailoverNotIdempotent(t *testing.T) {
+	broken, hits := brokenServer(t)
+	live := tokenServer(t, "secret")
+
+	// connection refused, the call was never received and can be sent elsewhere
+	api, err := zapi.NewAPI(zapi.Config{Url: deadURL(), Urls: []string{live.URL}, Token: "secret", Version: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.HostsCreate(zapi.Hosts{{Host: "web"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// connection lost, the broken frontend may have created the host
+	rec := &endpointRecorder{}
+	api, err = zapi.NewAPI(zapi.Config{Url: broken.URL, Urls: []string{live.URL}, Token: "secret", Version: 50000,
+		Middleware: []zapi.Middleware{rec.middleware}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = api.HostsCreate(zapi.Hosts{{Host: "web"}}); err == nil {
+		t.Error("Expected the create to fail")
+	}
+	if hits() != 1 {
+		t.Errorf("Expected 1 request to the broken endpoint, got %d", hits())
+	}
+
+	// the broken frontend is not promoted, next calls go to the live one
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if rec.last() != live.URL || hits() != 1 {
+		t.Errorf("Served by %q after %d broken requests", rec.last(), hits())
+	}
+}
+
+func TestFailoverServerError(t *testing.T) {
+	var mu sync.Mutex
+	hits := 0
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		http.Error(w, "upstream down", http.StatusBadGateway)
+	}))
+	defer unavailable.Close()
+	live := tokenServer(t, "secret")
+
+	api, err := zapi.NewAPI(zapi.Config{Url: unavailable.URL, Urls: []string{live.URL}, Token: "secret", Version: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var endpoint string
+	ctx := zapi.WithEndpointReport(context.Background(), func(url string) { endpoint = url })
+	if _, err = api.HostsGetContext(ctx, zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if endpoint != live.URL || hits != 1 {
+		t.Errorf("Served by %q after %d requests to the failing frontend", endpoint, hits)
+	}
+
+	// the failing frontend may have run the call before its proxy gave up
+	api, err = zapi.NewAPI(zapi.Config{Url: unavailable.URL, Urls: []string{live.URL}, Token: "secret", Version: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint = ""
+	err = api.HostsCreateContext(ctx, zapi.Hosts{{Host: "web"}})
+	var e *zapi.HTTPError
+	if !errors.As(err, &e) || e.StatusCode != http.StatusBadGateway || endpoint != "" {
+		t.Errorf("Expected the 502 without failover, got %v from %q", err, endpoint)
+	}
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	broken, _ := brokenServer(t)
+	live := tokenServer(t, "secret")
+	dead := deadURL()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: dead, Urls: []string{live.URL, broken.URL, live.URL}, Version: 50000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := api.CheckEndpoints(context.Background())
+	if len(res) != 3 {
+		t.Fatalf("Expected 3 endpoints, got %#v", res)
+	}
+	for i, exp := range []string{dead, live.URL, broken.URL} {
+		if res[i].URL != exp {
+			t.Errorf("%d: expected %s, got %s", i, exp, res[i].URL)
+		}
+	}
+	if res[0].Err == nil || res[2].Err == nil {
+		t.Errorf("Expected failures: %#v", res)
+	}
+	if res[1].Err != nil || res[1].Version != "5.0.0" {
+		t.Errorf("Expected 5.0.0: %#v", res[1])
+	}
+}
@@ -0,0 +1,58 @@
+package zabbix
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors matched by API errors through errors.Is, e.g.
+//
+//	if errors.Is(err, zabbix.ErrAlreadyExists) {
+//		...
+//	}
+//
+// An API error can match several of them, Zabbix reports both missing objects
+// and missing permissions with the same message.
+var (
+	ErrAlreadyExists    = errors.New("zabbix: object already exists")
+	ErrNotFound         = errors.New("zabbix: object not found")
+	ErrPermissionDenied = errors.New("zabbix: permission denied")
+	ErrSessionExpired   = errors.New("zabbix: session expired")
+	ErrInvalidParams    = errors.New("zabbix: invalid params")
+	ErrMethodNotFound   = errors.New("zabbix: method not found")
+)
+
+// JSON-RPC error codes used by Zabbix
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// errorPatterns maps sentinel errors to the Error.Data fragments Zabbix uses for them
+var errorPatterns = map[error][]string{
+	ErrAlreadyExists:    {"already exists"},
+	ErrNotFound:         {"does not exist", "not found"},
+	ErrPermissionDenied: {"No permissions", "Permission denied", "You do not have permission"},
+	ErrSessionExpired:   {"Session terminated", "Not authorised", "Not authorized"},
+}
+
+// Is reports whether e belongs to the class of target, one of the Err* sentinels.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrInvalidParams:
+		return e.Code == codeInvalidParams
+	case ErrMethodNotFound:
+		return e.Code == codeMethodNotFound
+	}
+	for _, p := range errorPatterns[target] {
+		if strings.Contains(e.Data, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Is reports an empty result as ErrNotFound.
+func (e *ExpectedOneResult) Is(target error) bool {
+	return target == ErrNotFound && *e == 0
+}
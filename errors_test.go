@@ -0,0 +1,60 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestErrorIs(t *testing.T) {
+	for _, tc := range []struct {
+		err    *zapi.Error
+		target error
+		want   bool
+	}{
+		{&zapi.Error{Code: -32602, Data: `Host with the same name "a" already exists.`}, zapi.ErrAlreadyExists, true},
+		{&zapi.Error{Code: -32602, Data: `Host with the same name "a" already exists.`}, zapi.ErrInvalidParams, true},
+		{&zapi.Error{Code: -32602, Data: "Session terminated, re-login, please."}, zapi.ErrSessionExpired, true},
+		{&zapi.Error{Code: -32500, Data: "No permissions to referred object or it does not exist!"}, zapi.ErrNotFound, true},
+		{&zapi.Error{Code: -32500, Data: "No permissions to referred object or it does not exist!"}, zapi.ErrPermissionDenied, true},
+		{&zapi.Error{Code: -32500, Data: "No permissions to referred object or it does not exist!"}, zapi.ErrInvalidParams, false},
+		{&zapi.Error{Code: -32601, Data: `Incorrect API "foo".`}, zapi.ErrMethodNotFound, true},
+		{&zapi.Error{Code: -32601, Data: `Incorrect API "foo".`}, zapi.ErrAlreadyExists, false},
+	} {
+		if got := errors.Is(tc.err, tc.target); got != tc.want {
+			t.Errorf("errors.Is(%q, %q) = %v", tc.err.Data, tc.target, got)
+		}
+	}
+}
+
+func TestErrorCall(t *testing.T) {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		if method == "hostgroup.get" {
+			return []interface{}{}, nil
+		}
+		return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: `Host group "a" already exists.`}
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.HostGroupsCreate(zapi.HostGroups{{Name: "a"}})
+	var e *zapi.Error
+	if !errors.As(err, &e) || !errors.Is(err, zapi.ErrAlreadyExists) {
+		t.Fatalf("Expected an already exists error, got %#v", err)
+	}
+	if e.Method != "hostgroup.create" || e.RequestID == 0 {
+		t.Errorf("Bad method or request id: %s", e)
+	}
+
+	_, err = api.HostGroupGetByID("42")
+	if !errors.Is(err, zapi.ErrNotFound) {
+		t.Errorf("Expected a not found error, got %#v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package zabbix
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	// EventSource is the type of the event
+	// see "source" in https://www.zabbix.com/documentation/5.0/manual/api/reference/event/object
+	EventSource int
+
+	// EventObject is the type of the object related to the event
+	// see "object" in https://www.zabbix.com/documentation/5.0/manual/api/reference/event/object
+	EventObject int
+
+	// AcknowledgeAction is a bitmask of event.acknowledge actions
+	// see "action" in https://www.zabbix.com/documentation/6.4/manual/api/reference/event/acknowledge
+	AcknowledgeAction int
+)
+
+const (
+	// EventSourceTrigger events created by triggers
+	EventSourceTrigger EventSource = 0
+	// EventSourceDiscovery events created by network discovery
+	EventSourceDiscovery EventSource = 1
+	// EventSourceAutoRegistration events created by active agent autoregistration
+	EventSourceAutoRegistration EventSource = 2
+	// EventSourceInternal internal events
+	EventSourceInternal EventSource = 3
+)
+
+const (
+	// EventObjectTrigger trigger events
+	EventObjectTrigger EventObject = 0
+	// EventObjectDiscoveredHost discovered host events
+	EventObjectDiscoveredHost EventObject = 1
+	// EventObjectDiscoveredService discovered service events
+	EventObjectDiscoveredService EventObject = 2
+	// EventObjectAutoRegisteredHost auto-registered host events
+	EventObjectAutoRegisteredHost EventObject = 3
+	// EventObjectItem item events
+	EventObjectItem EventObject = 4
+	// EventObjectLLDRule LLD rule events
+	EventObjectLLDRule EventObject = 5
+)
+
+const (
+	// AckClose closes the problem
+	AckClose AcknowledgeAction = 1
+	// AckAcknowledge acknowledges the event
+	AckAcknowledge AcknowledgeAction = 2
+	// AckMessage adds a message
+	AckMessage AcknowledgeAction = 4
+	// AckChangeSeverity changes the severity
+	AckChangeSeverity AcknowledgeAction = 8
+	// AckUnacknowledge removes the acknowledgement (Zabbix 6.0+)
+	AckUnacknowledge AcknowledgeAction = 16
+	// AckSuppress suppresses the problem (Zabbix 6.4+)
+	AckSuppress AcknowledgeAction = 32
+	// AckUnsuppress cancels the suppression (Zabbix 6.4+)
+	AckUnsuppress AcknowledgeAction = 64
+)
+
+// Acknowledge represent an update of an event, returned by selectAcknowledges
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/event/object#acknowledge
+type Acknowledge struct {
+	AcknowledgeID string            `json:"acknowledgeid"`
+	UserID        string            `json:"userid"`
+	EventID       string            `json:"eventid"`
+	Clock         int64             `json:"clock,string"`
+	Message       string            `json:"message"`
+	Action        AcknowledgeAction `json:"action,string"`
+	OldSeverity   SeverityType      `json:"old_severity,string"`
+	NewSeverity   SeverityType      `json:"new_severity,string"`
+}
+
+// Acknowledges is an array of Acknowledge
+type Acknowledges []Acknowledge
+
+// Event represent Zabbix event object
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/event/object
+type Event struct {
+	EventID       string       `json:"eventid"`
+	Source        EventSource  `json:"source,string"`
+	Object        EventObject  `json:"object,string"`
+	ObjectID      string       `json:"objectid"`
+	Clock         int64        `json:"clock,string"`
+	Ns            int64        `json:"ns,string"`
+	Value         ValueType    `json:"value,string"`
+	Acknowledged  int          `json:"acknowledged,string"`
+	Name          string       `json:"name"`
+	Severity      SeverityType `json:"severity,string"`
+	REventID      string       `json:"r_eventid"`
+	CEventID      string       `json:"c_eventid"`
+	CorrelationID string       `json:"correlationid"`
+	UserID        string       `json:"userid"`
+	Suppressed    int          `json:"suppressed,string"`
+	Opdata        string       `json:"opdata"`
+
+	Hosts        Hosts        `json:"hosts,omitempty"`
+	Tags         Tags         `json:"tags,omitempty"`
+	Acknowledges Acknowledges `json:"acknowledges,omitempty"`
+}
+
+// Events is an array of Event
+type Events []Event
+
+// EventResource describes event objects
+var EventResource = &Resource[Event]{
+	Object:  "event",
+	IDField: "eventid",
+}
+
+// ProblemEvent represent Zabbix problem object, an event not resolved yet or recently resolved.
+// It is not named Problem, the trigger value.
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/problem/object
+type ProblemEvent struct {
+	EventID       string       `json:"eventid"`
+	Source        EventSource  `json:"source,string"`
+	Object        EventObject  `json:"object,string"`
+	ObjectID      string       `json:"objectid"`
+	Clock         int64        `json:"clock,string"`
+	Ns            int64        `json:"ns,string"`
+	REventID      string       `json:"r_eventid"`
+	RClock        int64        `json:"r_clock,string"`
+	RNs           int64        `json:"r_ns,string"`
+	CorrelationID string       `json:"correlationid"`
+	UserID        string       `json:"userid"`
+	Name          string       `json:"name"`
+	Acknowledged  int          `json:"acknowledged,string"`
+	Severity      SeverityType `json:"severity,string"`
+	Suppressed    int          `json:"suppressed,string"`
+	Opdata        string       `json:"opdata"`
+
+	Tags         Tags         `json:"tags,omitempty"`
+	Acknowledges Acknowledges `json:"acknowledges,omitempty"`
+}
+
+// ProblemEvents is an array of ProblemEvent
+type ProblemEvents []ProblemEvent
+
+// ProblemResource describes problem objects, they are read only
+var ProblemResource = &Resource[ProblemEvent]{
+	Object:  "problem",
+	IDField: "eventid",
+}
+
+// EventAcknowledgement holds the parameters of event.acknowledge
+// https://www.zabbix.com/documentation/6.4/manual/api/reference/event/acknowledge
+type EventAcknowledgement struct {
+	EventIDs []string
+	Action   AcknowledgeAction
+	// Message is required by AckMessage
+	Message string
+	// Severity is the new severity of AckChangeSeverity
+	Severity SeverityType
+	// SuppressUntil is the Unix time AckSuppress lasts until, 0 for an indefinite suppression
+	SuppressUntil int64
+}
+
+// Params returns the parameters sent to event.acknowledge, only those of the requested actions are set.
+func (a EventAcknowledgement) Params() Params {
+	p := Params{"eventids": a.EventIDs, "action": a.Action}
+	if a.Action&AckMessage != 0 {
+		p["message"] = a.Message
+	}
+	if a.Action&AckChangeSeverity != 0 {
+		p["severity"] = a.Severity
+	}
+	if a.Action&AckSuppress != 0 {
+		p["suppress_until"] = a.SuppressUntil
+	}
+	return p
+}
+
+// EventsGet Wrapper for event.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/event/get
+func (api *API) EventsGet(params Params) (res Events, err error) {
+	return api.EventsGetContext(context.Background(), params)
+}
+
+// EventsGetContext is like EventsGet but uses ctx for the request.
+func (api *API) EventsGetContext(ctx context.Context, params Params) (res Events, err error) {
+	return EventResource.Get(ctx, api, params)
+}
+
+// EventsQuery Wrapper for event.get taking typed options instead of Params.
+func (api *API) EventsQuery(q EventQuery) (res Events, err error) {
+	return api.EventsQueryContext(context.Background(), q)
+}
+
+// EventsQueryContext is like EventsQuery but uses ctx for the request.
+func (api *API) EventsQueryContext(ctx context.Context, q EventQuery) (res Events, err error) {
+	return EventResource.Query(ctx, api, q)
+}
+
+// EventGetByID Gets event by Id only if there is exactly 1 matching event.
+func (api *API) EventGetByID(id string) (res *Event, err error) {
+	return api.EventGetByIDContext(context.Background(), id)
+}
+
+// EventGetByIDContext is like EventGetByID but uses ctx for the request.
+func (api *API) EventGetByIDContext(ctx context.Context, id string) (res *Event, err error) {
+	return EventResource.GetOne(ctx, api, id)
+}
+
+// ProblemsGet Wrapper for problem.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/problem/get
+func (api *API) ProblemsGet(params Params) (res ProblemEvents, err error) {
+	return api.ProblemsGetContext(context.Background(), params)
+}
+
+// ProblemsGetContext is like ProblemsGet but uses ctx for the request.
+func (api *API) ProblemsGetContext(ctx context.Context, params Params) (res ProblemEvents, err error) {
+	return ProblemResource.Get(ctx, api, params)
+}
+
+// ProblemsQuery Wrapper for problem.get taking typed options instead of Params.
+func (api *API) ProblemsQuery(q ProblemQuery) (res ProblemEvents, err error) {
+	return api.ProblemsQueryContext(context.Background(), q)
+}
+
+// ProblemsQueryContext is like ProblemsQuery but uses ctx for the request.
+func (api *API) ProblemsQueryContext(ctx context.Context, q ProblemQuery) (res ProblemEvents, err error) {
+	return ProblemResource.Query(ctx, api, q)
+}
+
+// EventsAcknowledge Wrapper for event.acknowledge
+// Returns the ids of the updated events.
+// https://www.zabbix.com/documentation/6.4/manual/api/reference/event/acknowledge
+func (api *API) EventsAcknowledge(ack EventAcknowledgement) (eventids []string, err error) {
+	return api.EventsAcknowledgeContext(context.Background(), ack)
+}
+
+// EventsAcknowledgeContext is like EventsAcknowledge but uses ctx for the request.
+func (api *API) EventsAcknowledgeContext(ctx context.Context, ack EventAcknowledgement) (eventids []string, err error) {
+	if ack.Action == 0 {
+		return nil, fmt.Errorf("event.acknowledge: no action")
+	}
+	if ack.Action&AckMessage != 0 && ack.Message == "" {
+		return nil, fmt.Errorf("event.acknowledge: AckMessage without a message")
+	}
+	if err = api.detectVersion(ctx); err != nil {
+		return
+	}
+	if ack.Action&AckUnacknowledge != 0 {
+		if err = api.require(CapUnacknowledge, "event.acknowledge"); err != nil {
+			return
+		}
+	}
+	if ack.Action&(AckSuppress|AckUnsuppress) != 0 {
+		if err = api.require(CapEventSuppress, "event.acknowledge"); err != nil {
+			return
+		}
+	}
+	return api.callIDs(ctx, "event.acknowledge", ack.Params(), "eventids")
+}
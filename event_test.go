@@ -0,0 +1,137 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+const testEvents = `[{
+	"eventid": "42", "source": "0", "object": "0", "objectid": "13", "clock": "1700000000", "ns": "5",
+	"value": "1", "acknowledged": "1", "name": "High load", "severity": "4", "r_eventid": "0",
+	"hosts": [{"hostid": "10084", "host": "web", "name": "web"}],
+	"tags": [{"tag": "service", "value": "http"}],
+	"acknowledges": [{"acknowledgeid": "7", "userid": "1", "eventid": "42", "clock": "1700000100",
+		"message": "on it", "action": "6", "old_severity": "0", "new_severity": "0"}]
+}]`
+
+func eventAPI(t *testing.T, version string) (*zapi.API, map[string]json.RawMessage) {
+	sent := map[string]json.RawMessage{}
+	srv := newRPCServer(t, version, func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		sent[method] = params
+		switch method {
+		case "event.get", "problem.get":
+			return json.RawMessage(testEvents), nil
+		case "event.acknowledge":
+			var p struct {
+				EventIDs []string `json:"eventids"`
+			}
+			json.Unmarshal(params, &p)
+			return map[string][]string{"eventids": p.EventIDs}, nil
+		}
+		return nil, &zapi.Error{Code: -32602, Message: "Invalid params."}
+	})
+	t.Cleanup(srv.Close)
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, sent
+}
+
+func TestEventsGet(t *testing.T) {
+	api, sent := eventAPI(t, "6.0.0")
+
+	unacked := false
+	events, err := api.EventsQuery(zapi.EventQuery{
+		HostIDs:            []string{"10084"},
+		Acknowledged:       &unacked,
+		Severities:         []zapi.SeverityType{zapi.High, zapi.Critical},
+		TimeFrom:           1700000000,
+		SelectHosts:        true,
+		SelectTags:         true,
+		SelectAcknowledges: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"acknowledged":false,"hostids":["10084"],"object":0,"output":"extend","selectHosts":"extend","selectTags":"extend","select_acknowledges":"extend","severities":[4,5],"source":0,"time_from":1700000000}`
+	if string(sent["event.get"]) != expected {
+		t.Errorf("Expected %s, got %s", expected, sent["event.get"])
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	e := events[0]
+	if e.EventID != "42" || e.Value != zapi.Problem || e.Severity != zapi.High || e.Clock != 1700000000 {
+		t.Errorf("Bad event: %#v", e)
+	}
+	if len(e.Hosts) != 1 || e.Hosts[0].HostID != "10084" {
+		t.Errorf("Bad hosts: %#v", e.Hosts)
+	}
+	if !reflect.DeepEqual(e.Tags, zapi.Tags{{Tag: "service", Value: "http"}}) {
+		t.Errorf("Bad tags: %#v", e.Tags)
+	}
+	if len(e.Acknowledges) != 1 || e.Acknowledges[0].Action != zapi.AckAcknowledge|zapi.AckMessage {
+		t.Errorf("Bad acknowledges: %#v", e.Acknowledges)
+	}
+}
+
+func TestProblemsGet(t *testing.T) {
+	api, sent := eventAPI(t, "6.0.0")
+
+	problems, err := api.ProblemsQuery(zapi.ProblemQuery{Recent: true, SelectAcknowledges: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"object":0,"output":"extend","recent":true,"selectAcknowledges":"extend","source":0}`
+	if string(sent["problem.get"]) != expected {
+		t.Errorf("Expected %s, got %s", expected, sent["problem.get"])
+	}
+	if len(problems) != 1 || problems[0].Name != "High load" || len(problems[0].Acknowledges) != 1 {
+		t.Errorf("Bad problems: %#v", problems)
+	}
+}
+
+func TestEventsAcknowledge(t *testing.T) {
+	api, sent := eventAPI(t, "6.4.0")
+
+	ids, err := api.EventsAcknowledge(zapi.EventAcknowledgement{
+		EventIDs: []string{"42", "43"},
+		Action:   zapi.AckAcknowledge | zapi.AckMessage | zapi.AckChangeSeverity | zapi.AckSuppress,
+		Message:  "on it",
+		Severity: zapi.Average,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"42", "43"}) {
+		t.Errorf("Bad ids: %v", ids)
+	}
+	expected := `{"action":46,"eventids":["42","43"],"message":"on it","severity":3,"suppress_until":0}`
+	if string(sent["event.acknowledge"]) != expected {
+		t.Errorf("Expected %s, got %s", expected, sent["event.acknowledge"])
+	}
+
+	if _, err = api.EventsAcknowledge(zapi.EventAcknowledgement{EventIDs: []string{"42"}, Action: zapi.AckMessage}); err == nil {
+		t.Error("Expected an error for a missing message")
+	}
+
+	api, sent = eventAPI(t, "6.0.0")
+	_, err = api.EventsAcknowledge(zapi.EventAcknowledgement{EventIDs: []string{"42"}, Action: zapi.AckUnsuppress})
+	if !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, ok := sent["event.acknowledge"]; ok {
+		t.Error("Unsupported acknowledge sent")
+	}
+	if _, err = api.EventsAcknowledge(zapi.EventAcknowledgement{EventIDs: []string{"42"}, Action: zapi.AckClose | zapi.AckUnacknowledge}); err != nil {
+		t.Error(err)
+	}
+}
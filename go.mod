@@ -1,5 +1,5 @@
 module github.com/tpretz/go-zabbix-api
 
-go 1.12
+go 1.21
 
 require github.com/AlekSi/reflector v0.4.1 // indirect
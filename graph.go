@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 type (
 	GraphType string
 	GraphAxis string
@@ -80,150 +82,122 @@ type Graph struct {
 // HostGroups is an array of HostGroup
 type Graphs []Graph
 
+// GraphResource describes graph objects
+var GraphResource = &Resource[Graph]{
+	Object:  "graph",
+	IDField: "graphid",
+}
+
+// GraphPrototypeResource describes graphprototype objects
+var GraphPrototypeResource = &Resource[Graph]{
+	Object:  "graphprototype",
+	IDField: "graphid",
+}
+
 // GraphsGet Wrapper for graph.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/get
 func (api *API) GraphsGet(params Params) (res Graphs, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("graph.get", params, &res)
-	return
+	return api.GraphsGetContext(context.Background(), params)
+}
+
+// GraphsGetContext is like GraphsGet but uses ctx for the request.
+func (api *API) GraphsGetContext(ctx context.Context, params Params) (res Graphs, err error) {
+	return GraphResource.Get(ctx, api, params)
 }
 func (api *API) GraphProtosGet(params Params) (res Graphs, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("graphprototype.get", params, &res)
-	return
+	return api.GraphProtosGetContext(context.Background(), params)
+}
+
+func (api *API) GraphProtosGetContext(ctx context.Context, params Params) (res Graphs, err error) {
+	return GraphPrototypeResource.Get(ctx, api, params)
 }
 
 // GraphGetByID Gets host group by Id only if there is exactly 1 matching host group.
 func (api *API) GraphGetByID(id string) (res *Graph, err error) {
-	groups, err := api.GraphsGet(Params{"graphids": id})
-	if err != nil {
-		return
-	}
-
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
-	}
-	return
+	return api.GraphGetByIDContext(context.Background(), id)
+}
+
+// GraphGetByIDContext is like GraphGetByID but uses ctx for the request.
+func (api *API) GraphGetByIDContext(ctx context.Context, id string) (res *Graph, err error) {
+	return GraphResource.GetOne(ctx, api, id)
 }
 func (api *API) GraphProtoGetByID(id string) (res *Graph, err error) {
-	groups, err := api.GraphProtosGet(Params{"graphids": id})
-	if err != nil {
-		return
-	}
+	return api.GraphProtoGetByIDContext(context.Background(), id)
+}
 
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
-	}
-	return
+func (api *API) GraphProtoGetByIDContext(ctx context.Context, id string) (res *Graph, err error) {
+	return GraphPrototypeResource.GetOne(ctx, api, id)
 }
 
 // GraphsCreate Wrapper for graph.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/create
 func (api *API) GraphsCreate(hostGroups Graphs) (err error) {
-	response, err := api.CallWithError("graph.create", hostGroups)
-	if err != nil {
-		return
-	}
-
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
-	for i, id := range groupids {
-		hostGroups[i].GraphID = id.(string)
-	}
-	return
+	return api.GraphsCreateContext(context.Background(), hostGroups)
+}
+
+// GraphsCreateContext is like GraphsCreate but uses ctx for the request.
+func (api *API) GraphsCreateContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphResource.Create(ctx, api, hostGroups)
 }
 func (api *API) GraphProtosCreate(hostGroups Graphs) (err error) {
-	response, err := api.CallWithError("graphprototype.create", hostGroups)
-	if err != nil {
-		return
-	}
+	return api.GraphProtosCreateContext(context.Background(), hostGroups)
+}
 
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
-	for i, id := range groupids {
-		hostGroups[i].GraphID = id.(string)
-	}
-	return
+func (api *API) GraphProtosCreateContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphPrototypeResource.Create(ctx, api, hostGroups)
 }
 
 // GraphsUpdate Wrapper for graph.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/graph/update
 func (api *API) GraphsUpdate(hostGroups Graphs) (err error) {
-	_, err = api.CallWithError("graph.update", hostGroups)
-	return
+	return api.GraphsUpdateContext(context.Background(), hostGroups)
+}
+
+// GraphsUpdateContext is like GraphsUpdate but uses ctx for the request.
+func (api *API) GraphsUpdateContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphResource.Update(ctx, api, hostGroups)
 }
 func (api *API) GraphProtosUpdate(hostGroups Graphs) (err error) {
-	_, err = api.CallWithError("graphprototype.update", hostGroups)
-	return
+	return api.GraphProtosUpdateContext(context.Background(), hostGroups)
+}
+
+func (api *API) GraphProtosUpdateContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphPrototypeResource.Update(ctx, api, hostGroups)
 }
 
 // HostGroupsDelete Wrapper for hostgroup.delete
 // Cleans GroupId in all hostGroups elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/delete
 func (api *API) GraphsDelete(hostGroups Graphs) (err error) {
-	ids := make([]string, len(hostGroups))
-	for i, group := range hostGroups {
-		ids[i] = group.GraphID
-	}
-
-	err = api.GraphsDeleteByIds(ids)
-	if err == nil {
-		for i := range hostGroups {
-			hostGroups[i].GraphID = ""
-		}
-	}
-	return
+	return api.GraphsDeleteContext(context.Background(), hostGroups)
+}
+
+// GraphsDeleteContext is like GraphsDelete but uses ctx for the request.
+func (api *API) GraphsDeleteContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphResource.Delete(ctx, api, hostGroups)
 }
 func (api *API) GraphProtosDelete(hostGroups Graphs) (err error) {
-	ids := make([]string, len(hostGroups))
-	for i, group := range hostGroups {
-		ids[i] = group.GraphID
-	}
+	return api.GraphProtosDeleteContext(context.Background(), hostGroups)
+}
 
-	err = api.GraphProtosDeleteByIds(ids)
-	if err == nil {
-		for i := range hostGroups {
-			hostGroups[i].GraphID = ""
-		}
-	}
-	return
+func (api *API) GraphProtosDeleteContext(ctx context.Context, hostGroups Graphs) (err error) {
+	return GraphPrototypeResource.Delete(ctx, api, hostGroups)
 }
 
 // HostGroupsDeleteByIds Wrapper for hostgroup.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/delete
 func (api *API) GraphsDeleteByIds(ids []string) (err error) {
-	response, err := api.CallWithError("graph.delete", ids)
-	if err != nil {
-		return
-	}
-
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
-	if len(ids) != len(groupids) {
-		err = &ExpectedMore{len(ids), len(groupids)}
-	}
-	return
+	return api.GraphsDeleteByIdsContext(context.Background(), ids)
+}
+
+// GraphsDeleteByIdsContext is like GraphsDeleteByIds but uses ctx for the request.
+func (api *API) GraphsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return GraphResource.DeleteByIDs(ctx, api, ids)
 }
 func (api *API) GraphProtosDeleteByIds(ids []string) (err error) {
-	response, err := api.CallWithError("graphprototype.delete", ids)
-	if err != nil {
-		return
-	}
-
-	result := response.Result.(map[string]interface{})
-	groupids := result["graphids"].([]interface{})
-	if len(ids) != len(groupids) {
-		err = &ExpectedMore{len(ids), len(groupids)}
-	}
-	return
+	return api.GraphProtosDeleteByIdsContext(context.Background(), ids)
+}
+
+func (api *API) GraphProtosDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return GraphPrototypeResource.DeleteByIDs(ctx, api, ids)
 }
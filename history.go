@@ -0,0 +1,438 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// LogEntry is the value of a Log item
+type LogEntry struct {
+	// ID is the id of the history record
+	ID string
+	// Timestamp is the time of the entry in the log, if the item extracts it
+	Timestamp int64
+	// Source and Severity are set for Windows event log entries
+	Source     string
+	Severity   int
+	LogEventID int64
+	Value      string
+}
+
+// History is a value collected by an item
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/history/object
+type History struct {
+	ItemID    string
+	Clock     int64
+	Ns        int64
+	ValueType ValueType
+	// Value is a float64 for Float items, a uint64 for Unsigned items,
+	// a string for Character and Text items and a LogEntry for Log items.
+	Value interface{}
+}
+
+// HistoryValues is an array of History
+type HistoryValues []History
+
+// Float64 returns the value of a Float or Unsigned item, false for other types.
+func (h History) Float64() (float64, bool) {
+	switch v := h.Value.(type) {
+	case float64:
+		return v, true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// String returns the value as text, the log line for Log items.
+func (h History) String() string {
+	switch v := h.Value.(type) {
+	case LogEntry:
+		return v.Value
+	case string:
+		return v
+	}
+	return fmt.Sprint(h.Value)
+}
+
+// rawHistory is the shape of all history objects, fields of log history are empty for other types
+type rawHistory struct {
+	ID         string `json:"id"`
+	ItemID     string `json:"itemid"`
+	Clock      int64  `json:"clock,string"`
+	Ns         int64  `json:"ns,string"`
+	Value      string `json:"value"`
+	Timestamp  int64  `json:"timestamp,string"`
+	Source     string `json:"source"`
+	Severity   int    `json:"severity,string"`
+	LogEventID int64  `json:"logeventid,string"`
+}
+
+func (r *rawHistory) decode(t ValueType) (h History, err *DecodeError) {
+	h = History{ItemID: r.ItemID, Clock: r.Clock, Ns: r.Ns, ValueType: t}
+	var e error
+	switch t {
+	case Float:
+		h.Value, e = strconv.ParseFloat(r.Value, 64)
+	case Unsigned:
+		h.Value, e = strconv.ParseUint(r.Value, 10, 64)
+	case Log:
+		h.Value = LogEntry{r.ID, r.Timestamp, r.Source, r.Severity, r.LogEventID, r.Value}
+	default:
+		h.Value = r.Value
+	}
+	if e != nil {
+		return h, &DecodeError{"history", r.ItemID, "value", e}
+	}
+	return
+}
+
+// HistoryQuery holds the options of history.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/history/get
+type HistoryQuery struct {
+	CommonQuery
+	// ValueType is the type of the items, values are read from the history table of this type
+	ValueType ValueType
+	ItemIDs   []string
+	HostIDs   []string
+	// TimeFrom and TimeTill are Unix times bounding the values, ignored if zero
+	TimeFrom int64
+	TimeTill int64
+}
+
+// Params implements Query.
+func (q HistoryQuery) Params() Params {
+	return q.with(func(p Params) {
+		p["history"] = q.ValueType
+		setIDs(p, "itemids", q.ItemIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setTime(p, "time_from", q.TimeFrom)
+		setTime(p, "time_till", q.TimeTill)
+	})
+}
+
+// HistoryGet Wrapper for history.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/history/get
+func (api *API) HistoryGet(q HistoryQuery) (res HistoryValues, err error) {
+	return api.HistoryGetContext(context.Background(), q)
+}
+
+// HistoryGetContext is like HistoryGet but uses ctx for the request.
+func (api *API) HistoryGetContext(ctx context.Context, q HistoryQuery) (res HistoryValues, err error) {
+	return api.historyGet(ctx, q.Params(), q.ValueType)
+}
+
+func (api *API) historyGet(ctx context.Context, params Params, t ValueType) (res HistoryValues, err error) {
+	var raw []rawHistory
+	if err = api.CallWithErrorParseContext(ctx, "history.get", params, &raw); err != nil {
+		return
+	}
+	res = make(HistoryValues, 0, len(raw))
+	for i := range raw {
+		h, e := raw[i].decode(t)
+		if e != nil {
+			if err = api.decodeFailed(e); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		res = append(res, h)
+	}
+	return
+}
+
+// HistoryGetByItems Gets the history of items, with one history.get call per value type of the items.
+// ValueType, ItemIDs and HostIDs of q are ignored, Limit applies to each call.
+func (api *API) HistoryGetByItems(items Items, q HistoryQuery) (res HistoryValues, err error) {
+	return api.HistoryGetByItemsContext(context.Background(), items, q)
+}
+
+// HistoryGetByItemsContext is like HistoryGetByItems but uses ctx for the request.
+func (api *API) HistoryGetByItemsContext(ctx context.Context, items Items, q HistoryQuery) (res HistoryValues, err error) {
+	byType := map[ValueType][]string{}
+	for _, item := range items {
+		byType[item.ValueType] = append(byType[item.ValueType], item.ItemID)
+	}
+	types := make([]ValueType, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	for _, t := range types {
+		q.ValueType, q.ItemIDs, q.HostIDs = t, byType[t], nil
+		values, err := api.HistoryGetContext(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, values...)
+	}
+	return
+}
+
+// historyKey identifies a value for deduplication across pages
+type historyKey struct {
+	itemID    string
+	clock, ns int64
+}
+
+// HistoryGetPages Wrapper for history.get returning the values page by page in ascending time order.
+// History has no ids, so pages are split on Clock: Size is the number of values per page and After is ignored.
+// Limit, SortField and SortOrder of q are ignored.
+func (api *API) HistoryGetPages(ctx context.Context, q HistoryQuery, opts PageOptions, fn func(HistoryValues) error) error {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	params := func(from int64) Params {
+		p := q.Params()
+		if from != 0 {
+			p["time_from"] = from
+		}
+		p["sortfield"] = "clock"
+		p["sortorder"] = "ASC"
+		return p
+	}
+
+	from := q.TimeFrom
+	// values at Clock == from already delivered
+	var seen map[historyKey]bool
+	deliver := func(values HistoryValues) error {
+		fresh := values[:0]
+		for _, h := range values {
+			if !seen[historyKey{h.ItemID, h.Clock, h.Ns}] {
+				fresh = append(fresh, h)
+			}
+		}
+		if len(fresh) == 0 {
+			return nil
+		}
+		return fn(fresh)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p := params(from)
+		p["limit"] = size
+		page, err := api.historyGet(ctx, p, q.ValueType)
+		if err != nil {
+			return err
+		}
+		if len(page) < size {
+			return deliver(page)
+		}
+
+		last := page[len(page)-1].Clock
+		if last == from {
+			// a single second holds more than a page, get all of it then move to the next second
+			p = params(from)
+			p["time_till"] = from
+			if page, err = api.historyGet(ctx, p, q.ValueType); err != nil {
+				return err
+			}
+			if err = deliver(page); err != nil {
+				return err
+			}
+			if q.TimeTill != 0 && from >= q.TimeTill {
+				return nil
+			}
+			from, seen = from+1, nil
+			continue
+		}
+
+		if err = deliver(page); err != nil {
+			return err
+		}
+		seen = map[historyKey]bool{}
+		for _, h := range page {
+			if h.Clock == last {
+				seen[historyKey{h.ItemID, h.Clock, h.Ns}] = true
+			}
+		}
+		from = last
+	}
+}
+
+// HistoryGetStream is like HistoryGetPages but delivers values one by one on a channel.
+// The error channel is closed once all values are delivered, after receiving the error that stopped the stream if any.
+func (api *API) HistoryGetStream(ctx context.Context, q HistoryQuery, opts PageOptions) (<-chan History, <-chan error) {
+	return streamPages(ctx, func(page func([]History) error) error {
+		return api.HistoryGetPages(ctx, q, opts, func(values HistoryValues) error {
+			return page(values)
+		})
+	})
+}
+
+// HistoryPushValue is a value pushed to a ZabbixTrapper or HTTPAgent item,
+// identified by ItemID or by Host and Key
+// https://www.zabbix.com/documentation/7.0/en/manual/api/reference/history/push
+type HistoryPushValue struct {
+	ItemID string `json:"itemid,omitempty"`
+	Host   string `json:"host,omitempty"`
+	Key    string `json:"key,omitempty"`
+	// Value is made by PushFloat, PushUint, PushText or PushLog according to the type of the item
+	Value PushedValue `json:"value"`
+	// Clock and Ns are the time of the value, the time it is received if Clock is zero
+	Clock int64 `json:"clock,omitempty"`
+	Ns    int64 `json:"ns,omitempty"`
+}
+
+// PushedValue is the value of a HistoryPushValue, with its value type
+type PushedValue struct {
+	valueType ValueType
+	value     interface{}
+}
+
+// PushFloat returns the value of a Float item.
+func PushFloat(v float64) PushedValue {
+	return PushedValue{Float, v}
+}
+
+// PushUint returns the value of an Unsigned item.
+func PushUint(v uint64) PushedValue {
+	return PushedValue{Unsigned, v}
+}
+
+// PushText returns the value of a Character or Text item.
+func PushText(v string) PushedValue {
+	return PushedValue{Text, v}
+}
+
+// PushLog returns the value of a Log item.
+func PushLog(v string) PushedValue {
+	return PushedValue{Log, v}
+}
+
+// ValueType returns the type of the value, false if it was not made by a Push function.
+func (v PushedValue) ValueType() (ValueType, bool) {
+	return v.valueType, v.value != nil
+}
+
+// check returns why v cannot be pushed
+func (v PushedValue) check() error {
+	if v.value == nil {
+		return errors.New("no value, make it with PushFloat, PushUint, PushText or PushLog")
+	}
+	if f, ok := v.value.(float64); ok && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return fmt.Errorf("float value %v cannot be stored", f)
+	}
+	return nil
+}
+
+// MarshalJSON encodes numbers for Float and Unsigned values and strings for other types.
+func (v PushedValue) MarshalJSON() ([]byte, error) {
+	if err := v.check(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v.value)
+}
+
+// HistoryPushResult is the outcome of a pushed value
+type HistoryPushResult struct {
+	ItemID string `json:"itemid"`
+	// Error is why the value was rejected, empty if it was accepted
+	Error string `json:"error"`
+}
+
+// HistoryPushResults is an array of HistoryPushResult, in the order of the pushed values
+type HistoryPushResults []HistoryPushResult
+
+// Failed returns the number of rejected values.
+func (r HistoryPushResults) Failed() (n int) {
+	for _, res := range r {
+		if res.Error != "" {
+			n++
+		}
+	}
+	return
+}
+
+// HistoryPush Wrapper for history.push, available since Zabbix 7.0.
+// Rejected values are reported in the results, err is only set if the call failed as a whole.
+// https://www.zabbix.com/documentation/7.0/en/manual/api/reference/history/push
+func (api *API) HistoryPush(values []HistoryPushValue) (res HistoryPushResults, err error) {
+	return api.HistoryPushContext(context.Background(), values)
+}
+
+// HistoryPushContext is like HistoryPush but uses ctx for the request.
+func (api *API) HistoryPushContext(ctx context.Context, values []HistoryPushValue) (res HistoryPushResults, err error) {
+	for i, v := range values {
+		if v.ItemID == "" && (v.Host == "" || v.Key == "") {
+			return nil, fmt.Errorf("history.push: value %d has neither an itemid nor a host and key", i)
+		}
+		if err = v.Value.check(); err != nil {
+			return nil, fmt.Errorf("history.push: value %d: %w", i, err)
+		}
+	}
+	var raw struct {
+		Response string             `json:"response"`
+		Data     HistoryPushResults `json:"data"`
+	}
+	if err = api.CallWithErrorParseContext(ctx, "history.push", values, &raw); err != nil {
+		return
+	}
+	return raw.Data, nil
+}
+
+// Trend is the hourly aggregate of a numeric item
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/trend/object
+type Trend struct {
+	ItemID string `json:"itemid"`
+	// Clock is the beginning of the hour
+	Clock int64 `json:"clock,string"`
+	// Num is the number of values in the hour
+	Num int64   `json:"num,string"`
+	Min float64 `json:"value_min,string"`
+	Avg float64 `json:"value_avg,string"`
+	Max float64 `json:"value_max,string"`
+}
+
+// Trends is an array of Trend
+type Trends []Trend
+
+// TrendQuery holds the options of trend.get, only Float and Unsigned items have trends
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/trend/get
+type TrendQuery struct {
+	ItemIDs []string
+	// TimeFrom and TimeTill are Unix times bounding the trends, ignored if zero
+	TimeFrom int64
+	TimeTill int64
+	// Limit caps the number of returned trends, unlimited if zero
+	Limit int
+	// Extra holds parameters without a typed option, they override typed ones
+	Extra Params
+}
+
+// Params implements Query.
+func (q TrendQuery) Params() Params {
+	p := Params{"output": "extend"}
+	setIDs(p, "itemids", q.ItemIDs)
+	setTime(p, "time_from", q.TimeFrom)
+	setTime(p, "time_till", q.TimeTill)
+	if q.Limit > 0 {
+		p["limit"] = q.Limit
+	}
+	for k, v := range q.Extra {
+		p[k] = v
+	}
+	return p
+}
+
+// TrendsGet Wrapper for trend.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/trend/get
+func (api *API) TrendsGet(q TrendQuery) (res Trends, err error) {
+	return api.TrendsGetContext(context.Background(), q)
+}
+
+// TrendsGetContext is like TrendsGet but uses ctx for the request.
+func (api *API) TrendsGetContext(ctx context.Context, q TrendQuery) (res Trends, err error) {
+	err = api.CallWithErrorParseContext(ctx, "trend.get", q.Params(), &res)
+	return
+}
@@ -0,0 +1,267 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+// historyAPI serves history.get from values, objects of the history table of each value type
+func historyAPI(t *testing.T, values map[zapi.ValueType][]map[string]string) (*zapi.API, func() []json.RawMessage) {
+	var mu sync.Mutex
+	var calls []json.RawMessage
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		mu.Lock()
+		calls = append(calls, params)
+		mu.Unlock()
+		switch method {
+		case "trend.get":
+			return []map[string]string{{"itemid": "1", "clock": "1700002800", "num": "60", "value_min": "0.5", "value_avg": "1.25", "value_max": "3"}}, nil
+		case "history.get":
+		default:
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params."}
+		}
+		var p struct {
+			History  zapi.ValueType `json:"history"`
+			ItemIDs  []string       `json:"itemids"`
+			TimeFrom int64          `json:"time_from"`
+			TimeTill int64          `json:"time_till"`
+			Limit    int            `json:"limit"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: err.Error()}
+		}
+		var res []map[string]string
+		for _, v := range values[p.History] {
+			var clock int64
+			fmt.Sscan(v["clock"], &clock)
+			if (p.TimeFrom != 0 && clock < p.TimeFrom) || (p.TimeTill != 0 && clock > p.TimeTill) {
+				continue
+			}
+			if len(p.ItemIDs) > 0 && !contains(p.ItemIDs, v["itemid"]) {
+				continue
+			}
+			res = append(res, v)
+		}
+		sort.SliceStable(res, func(i, j int) bool { return res[i]["clock"] < res[j]["clock"] })
+		if p.Limit > 0 && len(res) > p.Limit {
+			res = res[:p.Limit]
+		}
+		return res, nil
+	})
+	t.Cleanup(srv.Close)
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, func() []json.RawMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHistoryGet(t *testing.T) {
+	api, calls := historyAPI(t, map[zapi.ValueType][]map[string]string{
+		zapi.Float:    {{"itemid": "1", "clock": "1700000000", "ns": "10", "value": "1.5"}},
+		zapi.Unsigned: {{"itemid": "2", "clock": "1700000001", "ns": "0", "value": "18446744073709551615"}},
+		zapi.Text:     {{"itemid": "3", "clock": "1700000002", "ns": "0", "value": "hello"}},
+		zapi.Log: {{"id": "9", "itemid": "4", "clock": "1700000003", "ns": "0", "value": "Service started",
+			"timestamp": "1700000001", "source": "Service Control Manager", "severity": "1", "logeventid": "7036"}},
+	})
+
+	values, err := api.HistoryGet(zapi.HistoryQuery{ValueType: zapi.Float, ItemIDs: []string{"1"}, TimeFrom: 1699999999})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := zapi.HistoryValues{{ItemID: "1", Clock: 1700000000, Ns: 10, ValueType: zapi.Float, Value: 1.5}}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, values)
+	}
+	if f, ok := values[0].Float64(); !ok || f != 1.5 {
+		t.Errorf("Bad Float64: %v %v", f, ok)
+	}
+	if p := string(calls()[0]); p != `{"history":0,"itemids":["1"],"output":"extend","time_from":1699999999}` {
+		t.Errorf("Bad params: %s", p)
+	}
+
+	items := zapi.Items{
+		{ItemID: "4", ValueType: zapi.Log},
+		{ItemID: "2", ValueType: zapi.Unsigned},
+		{ItemID: "3", ValueType: zapi.Text},
+	}
+	values, err = api.HistoryGetByItems(items, zapi.HistoryQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ordered by value type
+	if len(values) != 3 {
+		t.Fatalf("Expected 3 values, got %#v", values)
+	}
+	if v, ok := values[1].Value.(uint64); !ok || v != 18446744073709551615 {
+		t.Errorf("Bad unsigned: %#v", values[1])
+	}
+	if values[2].Value != "hello" || values[2].String() != "hello" {
+		t.Errorf("Bad text: %#v", values[2])
+	}
+	entry := zapi.LogEntry{ID: "9", Timestamp: 1700000001, Source: "Service Control Manager", Severity: 1, LogEventID: 7036, Value: "Service started"}
+	if values[0].Value != entry || values[0].String() != "Service started" {
+		t.Errorf("Bad log: %#v", values[0])
+	}
+	if _, ok := values[0].Float64(); ok {
+		t.Error("Log value is not a number")
+	}
+}
+
+func TestHistoryDecodeError(t *testing.T) {
+	api, _ := historyAPI(t, map[zapi.ValueType][]map[string]string{
+		zapi.Unsigned: {{"itemid": "2", "clock": "1700000001", "ns": "0", "value": "-1"}},
+	})
+	_, err := api.HistoryGet(zapi.HistoryQuery{ValueType: zapi.Unsigned})
+	var e *zapi.DecodeError
+	if !errors.As(err, &e) || e.Object != "history" || e.ID != "2" {
+		t.Errorf("Expected a DecodeError, got %v", err)
+	}
+}
+
+func TestHistoryGetPages(t *testing.T) {
+	// 5 values share a second, more than a page
+	clocks := []int64{100, 100, 101, 102, 102, 103, 103, 103, 103, 103, 104, 105}
+	var stored []map[string]string
+	for i, c := range clocks {
+		stored = append(stored, map[string]string{"itemid": "1", "clock": fmt.Sprint(c), "ns": fmt.Sprint(i), "value": fmt.Sprint(i)})
+	}
+	api, _ := historyAPI(t, map[zapi.ValueType][]map[string]string{zapi.Unsigned: stored})
+
+	var got []uint64
+	pages := 0
+	err := api.HistoryGetPages(context.Background(), zapi.HistoryQuery{ValueType: zapi.Unsigned, TimeTill: 104}, zapi.PageOptions{Size: 3}, func(values zapi.HistoryValues) error {
+		pages++
+		if len(values) > 5 {
+			t.Errorf("Page too large: %d", len(values))
+		}
+		for _, h := range values {
+			got = append(got, h.Value.(uint64))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []uint64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v in %d pages", expected, got, pages)
+	}
+
+	values, errc := api.HistoryGetStream(context.Background(), zapi.HistoryQuery{ValueType: zapi.Unsigned}, zapi.PageOptions{Size: 2})
+	n := 0
+	for range values {
+		n++
+	}
+	if err = <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n != len(clocks) {
+		t.Errorf("Expected %d values, got %d", len(clocks), n)
+	}
+}
+
+func TestTrendsGet(t *testing.T) {
+	api, calls := historyAPI(t, nil)
+	trends, err := api.TrendsGet(zapi.TrendQuery{ItemIDs: []string{"1"}, TimeFrom: 1700000000, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := zapi.Trends{{ItemID: "1", Clock: 1700002800, Num: 60, Min: 0.5, Avg: 1.25, Max: 3}}
+	if !reflect.DeepEqual(trends, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, trends)
+	}
+	if p := string(calls()[0]); p != `{"itemids":["1"],"limit":10,"output":"extend","time_from":1700000000}` {
+		t.Errorf("Bad params: %s", p)
+	}
+}
+
+func TestHistoryPush(t *testing.T) {
+	var sent json.RawMessage
+	srv := newRPCServer(t, "7.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		if method != "history.push" {
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params."}
+		}
+		sent = params
+		return json.RawMessage(`{"response": "success", "data": [{"itemid": "10600"}, {"itemid": "10601", "error": "Item is disabled."}]}`), nil
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []zapi.HistoryPushValue{
+		{ItemID: "10600", Value: zapi.PushFloat(0.5), Clock: 1700000000, Ns: 5},
+		{Host: "web", Key: "backup.status", Value: zapi.PushText("ok")},
+		{ItemID: "10601", Value: zapi.PushUint(18446744073709551615)},
+		{ItemID: "10602", Value: zapi.PushLog("started")},
+	}
+	res, err := api.HistoryPush(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"itemid":"10600","value":0.5,"clock":1700000000,"ns":5},{"host":"web","key":"backup.status","value":"ok"},` +
+		`{"itemid":"10601","value":18446744073709551615},{"itemid":"10602","value":"started"}]`
+	if string(sent) != expected {
+		t.Errorf("Expected %s, got %s", expected, sent)
+	}
+	if len(res) != 2 || res.Failed() != 1 || res[1].Error != "Item is disabled." || res[0].ItemID != "10600" {
+		t.Errorf("Bad results: %#v", res)
+	}
+
+	if vt, ok := values[3].Value.ValueType(); !ok || vt != zapi.Log {
+		t.Errorf("Expected a Log value, got %v", vt)
+	}
+
+	// invalid values are refused before the call
+	sent = nil
+	for _, v := range []zapi.HistoryPushValue{
+		{Host: "web", Value: zapi.PushUint(1)},
+		{ItemID: "10600"},
+		{ItemID: "10600", Value: zapi.PushFloat(math.NaN())},
+	} {
+		if _, err = api.HistoryPush([]zapi.HistoryPushValue{v}); err == nil || sent != nil {
+			t.Errorf("Expected an error for %#v", v)
+		}
+	}
+
+	// refused before calling older servers
+	sent = nil
+	api, err = zapi.NewAPI(zapi.Config{Url: srv.URL, Version: 60400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = api.HistoryPush(values)
+	var e *zapi.UnsupportedError
+	if !errors.As(err, &e) || e.Capability != zapi.CapHistoryPush || sent != nil {
+		t.Errorf("Expected an UnsupportedError, got %v", err)
+	}
+	if _, err = api.CallWithError("history.push", values); !errors.Is(err, zapi.ErrUnsupported) {
+		t.Errorf("Expected ErrUnsupported from a raw call, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package zabbix
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 type (
 	// AvailableType (readonly) Availability of Zabbix agent
@@ -52,6 +55,9 @@ type Host struct {
 
 	RawInventoryMode *InventoryMode `json:"inventory_mode,string,omitempty"`
 	InventoryMode    InventoryMode  `json:"-"`
+	// InventoryModeSet sends InventoryMode on create and update,
+	// it is set on hosts read with their inventory_mode
+	InventoryModeSet bool `json:"-"`
 
 	// Fields below used only when creating hosts
 	GroupIds         HostGroupIDs   `json:"groups,omitempty"`
@@ -67,101 +73,139 @@ type Host struct {
 // Hosts is an array of Host
 type Hosts []Host
 
+// HostResource describes host objects
+var HostResource = &Resource[Host]{
+	Object:  "host",
+	IDField: "hostid",
+	Prepare: prepHosts,
+	Decode:  hostUnmarshal,
+}
+
 // HostsGet Wrapper for host.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/get
 func (api *API) HostsGet(params Params) (res Hosts, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("host.get", params, &res)
+	return api.HostsGetContext(context.Background(), params)
+}
 
-	// fix up host details if present
-	for i := 0; i < len(res); i++ {
-		h := res[i]
-		for j := 0; j < len(h.Interfaces); j++ {
-			in := h.Interfaces[j]
-			res[i].Interfaces[j].Details = nil
-			if len(in.RawDetails) == 0 {
-				continue
-			}
+// HostsGetContext is like HostsGet but uses ctx for the request.
+func (api *API) HostsGetContext(ctx context.Context, params Params) (res Hosts, err error) {
+	return HostResource.Get(ctx, api, params)
+}
 
-			asStr := string(in.RawDetails)
-			if asStr == "[]" {
-				continue
-			}
+// HostsQuery Wrapper for host.get taking typed options instead of Params.
+func (api *API) HostsQuery(q HostQuery) (res Hosts, err error) {
+	return api.HostsQueryContext(context.Background(), q)
+}
 
-			out := HostInterfaceDetail{}
-			// assume singular, if api changes, this will fault
-			err := json.Unmarshal(in.RawDetails, &out)
-			if err != nil {
-				api.printf("got error during unmarshal %s", err)
-				panic(err)
-			}
-			res[i].Interfaces[j].Details = &out
-		}
+// HostsQueryContext is like HostsQuery but uses ctx for the request.
+func (api *API) HostsQueryContext(ctx context.Context, q HostQuery) (res Hosts, err error) {
+	return HostResource.Query(ctx, api, q)
+}
+
+// HostsGetPages Wrapper for host.get returning the results page by page, see PageOptions.
+// fn is called for each page, an error returned by fn stops the pagination and is returned.
+func (api *API) HostsGetPages(ctx context.Context, params Params, opts PageOptions, fn func(Hosts) error) error {
+	return HostResource.GetPages(ctx, api, params, opts, func(hosts []Host) error {
+		return fn(hosts)
+	})
+}
+
+// HostsGetStream is like HostsGetPages but delivers hosts one by one on a channel.
+// The error channel is closed once all hosts are delivered, after receiving the error that stopped the stream if any.
+func (api *API) HostsGetStream(ctx context.Context, params Params, opts PageOptions) (<-chan Host, <-chan error) {
+	return HostResource.GetStream(ctx, api, params, opts)
+}
 
-		// omitted = disabled
-		if h.RawInventoryMode == nil {
-			res[i].InventoryMode = InventoryDisabled
-		} else {
-			res[i].InventoryMode = *h.RawInventoryMode
+func hostUnmarshal(h *Host) *DecodeError {
+	// fix up host details if present
+	for j := 0; j < len(h.Interfaces); j++ {
+		in := &h.Interfaces[j]
+		in.Details = nil
+		if len(in.RawDetails) == 0 {
+			continue
 		}
 
-		// fix up host inventory if present
-		if len(h.RawInventory) != 0 {
-			// if its an empty array
-			asStr := string(h.RawInventory)
-			if asStr == "[]" || asStr == "{}" {
-				continue
-			}
+		asStr := string(in.RawDetails)
+		if asStr == "[]" {
+			continue
+		}
 
-			// lets unbox
-			var inv Inventory
-			if err := json.Unmarshal(h.RawInventory, &inv); err != nil {
-				api.printf("got error during unmarshal %s", err)
-				panic(err)
-			}
-			res[i].Inventory = inv
+		out := HostInterfaceDetail{}
+		// assume singular
+		if err := json.Unmarshal(in.RawDetails, &out); err != nil {
+			return &DecodeError{"host", h.HostID, "interfaces.details", err}
 		}
+		in.Details = &out
+	}
 
+	// omitted = disabled
+	if h.RawInventoryMode == nil {
+		h.InventoryMode = InventoryDisabled
+	} else {
+		h.InventoryMode = *h.RawInventoryMode
+		h.InventoryModeSet = true
 	}
 
-	return
+	// fix up host inventory if present
+	if len(h.RawInventory) != 0 {
+		// if its an empty array
+		asStr := string(h.RawInventory)
+		if asStr == "[]" || asStr == "{}" {
+			return nil
+		}
+
+		// lets unbox
+		var inv Inventory
+		if err := json.Unmarshal(h.RawInventory, &inv); err != nil {
+			return &DecodeError{"host", h.HostID, "inventory", err}
+		}
+		h.Inventory = inv
+	}
+	return nil
 }
 
 // HostsGetByHostGroupIds Gets hosts by host group Ids.
 func (api *API) HostsGetByHostGroupIds(ids []string) (res Hosts, err error) {
-	return api.HostsGet(Params{"groupids": ids})
+	return api.HostsGetByHostGroupIdsContext(context.Background(), ids)
+}
+
+// HostsGetByHostGroupIdsContext is like HostsGetByHostGroupIds but uses ctx for the request.
+func (api *API) HostsGetByHostGroupIdsContext(ctx context.Context, ids []string) (res Hosts, err error) {
+	return api.HostsGetContext(ctx, Params{"groupids": ids})
 }
 
 // HostsGetByHostGroups Gets hosts by host groups.
 func (api *API) HostsGetByHostGroups(hostGroups HostGroups) (res Hosts, err error) {
+	return api.HostsGetByHostGroupsContext(context.Background(), hostGroups)
+}
+
+// HostsGetByHostGroupsContext is like HostsGetByHostGroups but uses ctx for the request.
+func (api *API) HostsGetByHostGroupsContext(ctx context.Context, hostGroups HostGroups) (res Hosts, err error) {
 	ids := make([]string, len(hostGroups))
 	for i, id := range hostGroups {
 		ids[i] = id.GroupID
 	}
-	return api.HostsGetByHostGroupIds(ids)
+	return api.HostsGetByHostGroupIdsContext(ctx, ids)
 }
 
 // HostGetByID Gets host by Id only if there is exactly 1 matching host.
 func (api *API) HostGetByID(id string) (res *Host, err error) {
-	hosts, err := api.HostsGet(Params{"hostids": id})
-	if err != nil {
-		return
-	}
+	return api.HostGetByIDContext(context.Background(), id)
+}
 
-	if len(hosts) == 1 {
-		res = &hosts[0]
-	} else {
-		e := ExpectedOneResult(len(hosts))
-		err = &e
-	}
-	return
+// HostGetByIDContext is like HostGetByID but uses ctx for the request.
+func (api *API) HostGetByIDContext(ctx context.Context, id string) (res *Host, err error) {
+	return HostResource.GetOne(ctx, api, id)
 }
 
 // HostGetByHost Gets host by Host only if there is exactly 1 matching host.
 func (api *API) HostGetByHost(host string) (res *Host, err error) {
-	hosts, err := api.HostsGet(Params{"filter": map[string]string{"host": host}})
+	return api.HostGetByHostContext(context.Background(), host)
+}
+
+// HostGetByHostContext is like HostGetByHost but uses ctx for the request.
+func (api *API) HostGetByHostContext(ctx context.Context, host string) (res *Host, err error) {
+	hosts, err := api.HostsGetContext(ctx, Params{"filter": map[string]string{"host": host}})
 	if err != nil {
 		return
 	}
@@ -176,7 +220,7 @@ func (api *API) HostGetByHost(host string) (res *Host, err error) {
 }
 
 // handle manual marshal
-func prepHosts(hosts Hosts) {
+func prepHosts(hosts []Host) {
 	for i := 0; i < len(hosts); i++ {
 		h := hosts[i]
 		for j := 0; j < len(h.Interfaces); j++ {
@@ -193,68 +237,54 @@ func prepHosts(hosts Hosts) {
 			asB, _ := json.Marshal(h.Inventory)
 			hosts[i].RawInventory = json.RawMessage(asB)
 		}
-		invMode := h.InventoryMode
-		h.RawInventoryMode = &invMode
+		if h.InventoryModeSet {
+			invMode := h.InventoryMode
+			hosts[i].RawInventoryMode = &invMode
+		}
 	}
 }
 
 // HostsCreate Wrapper for host.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/create
 func (api *API) HostsCreate(hosts Hosts) (err error) {
-	prepHosts(hosts)
-	response, err := api.CallWithError("host.create", hosts)
-	if err != nil {
-		return
-	}
+	return api.HostsCreateContext(context.Background(), hosts)
+}
 
-	result := response.Result.(map[string]interface{})
-	hostids := result["hostids"].([]interface{})
-	for i, id := range hostids {
-		hosts[i].HostID = id.(string)
-	}
-	return
+// HostsCreateContext is like HostsCreate but uses ctx for the request.
+func (api *API) HostsCreateContext(ctx context.Context, hosts Hosts) (err error) {
+	return HostResource.Create(ctx, api, hosts)
 }
 
 // HostsUpdate Wrapper for host.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/update
 func (api *API) HostsUpdate(hosts Hosts) (err error) {
-	prepHosts(hosts)
-	_, err = api.CallWithError("host.update", hosts)
-	return
+	return api.HostsUpdateContext(context.Background(), hosts)
+}
+
+// HostsUpdateContext is like HostsUpdate but uses ctx for the request.
+func (api *API) HostsUpdateContext(ctx context.Context, hosts Hosts) (err error) {
+	return HostResource.Update(ctx, api, hosts)
 }
 
 // HostsDelete Wrapper for host.delete
 // Cleans HostId in all hosts elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/delete
 func (api *API) HostsDelete(hosts Hosts) (err error) {
-	ids := make([]string, len(hosts))
-	for i, host := range hosts {
-		ids[i] = host.HostID
-	}
+	return api.HostsDeleteContext(context.Background(), hosts)
+}
 
-	err = api.HostsDeleteByIds(ids)
-	if err == nil {
-		for i := range hosts {
-			hosts[i].HostID = ""
-		}
-	}
-	return
+// HostsDeleteContext is like HostsDelete but uses ctx for the request.
+func (api *API) HostsDeleteContext(ctx context.Context, hosts Hosts) (err error) {
+	return HostResource.Delete(ctx, api, hosts)
 }
 
 // HostsDeleteByIds Wrapper for host.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/host/delete
 func (api *API) HostsDeleteByIds(ids []string) (err error) {
-	var response Response
-	response, err = api.CallWithError("host.delete", ids)
-
-	if err != nil {
-		return
-	}
+	return api.HostsDeleteByIdsContext(context.Background(), ids)
+}
 
-	result := response.Result.(map[string]interface{})
-	hostids := result["hostids"].([]interface{})
-	if len(ids) != len(hostids) {
-		err = &ExpectedMore{len(ids), len(hostids)}
-	}
-	return
+// HostsDeleteByIdsContext is like HostsDeleteByIds but uses ctx for the request.
+func (api *API) HostsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return HostResource.DeleteByIDs(ctx, api, ids)
 }
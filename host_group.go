@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 type (
 	// InternalType (readonly) Whether the group is used internally by the system. An internal group cannot be deleted.
 	// see "internal" in https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/object
@@ -24,6 +26,12 @@ type HostGroup struct {
 // HostGroups is an array of HostGroup
 type HostGroups []HostGroup
 
+// HostGroupResource describes hostgroup objects
+var HostGroupResource = &Resource[HostGroup]{
+	Object:  "hostgroup",
+	IDField: "groupid",
+}
+
 // HostGroupID represent Zabbix GroupID
 type HostGroupID struct {
 	GroupID string `json:"groupid"`
@@ -35,82 +43,75 @@ type HostGroupIDs []HostGroupID
 // HostGroupsGet Wrapper for hostgroup.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/get
 func (api *API) HostGroupsGet(params Params) (res HostGroups, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("hostgroup.get", params, &res)
-	return
+	return api.HostGroupsGetContext(context.Background(), params)
+}
+
+// HostGroupsGetContext is like HostGroupsGet but uses ctx for the request.
+func (api *API) HostGroupsGetContext(ctx context.Context, params Params) (res HostGroups, err error) {
+	return HostGroupResource.Get(ctx, api, params)
+}
+
+// HostGroupsQuery Wrapper for hostgroup.get taking typed options instead of Params.
+func (api *API) HostGroupsQuery(q HostGroupQuery) (res HostGroups, err error) {
+	return api.HostGroupsQueryContext(context.Background(), q)
+}
+
+// HostGroupsQueryContext is like HostGroupsQuery but uses ctx for the request.
+func (api *API) HostGroupsQueryContext(ctx context.Context, q HostGroupQuery) (res HostGroups, err error) {
+	return HostGroupResource.Query(ctx, api, q)
 }
 
 // HostGroupGetByID Gets host group by Id only if there is exactly 1 matching host group.
 func (api *API) HostGroupGetByID(id string) (res *HostGroup, err error) {
-	groups, err := api.HostGroupsGet(Params{"groupids": id})
-	if err != nil {
-		return
-	}
+	return api.HostGroupGetByIDContext(context.Background(), id)
+}
 
-	if len(groups) == 1 {
-		res = &groups[0]
-	} else {
-		e := ExpectedOneResult(len(groups))
-		err = &e
-	}
-	return
+// HostGroupGetByIDContext is like HostGroupGetByID but uses ctx for the request.
+func (api *API) HostGroupGetByIDContext(ctx context.Context, id string) (res *HostGroup, err error) {
+	return HostGroupResource.GetOne(ctx, api, id)
 }
 
 // HostGroupsCreate Wrapper for hostgroup.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/create
 func (api *API) HostGroupsCreate(hostGroups HostGroups) (err error) {
-	response, err := api.CallWithError("hostgroup.create", hostGroups)
-	if err != nil {
-		return
-	}
+	return api.HostGroupsCreateContext(context.Background(), hostGroups)
+}
 
-	result := response.Result.(map[string]interface{})
-	groupids := result["groupids"].([]interface{})
-	for i, id := range groupids {
-		hostGroups[i].GroupID = id.(string)
-	}
-	return
+// HostGroupsCreateContext is like HostGroupsCreate but uses ctx for the request.
+func (api *API) HostGroupsCreateContext(ctx context.Context, hostGroups HostGroups) (err error) {
+	return HostGroupResource.Create(ctx, api, hostGroups)
 }
 
 // HostGroupsUpdate Wrapper for hostgroup.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/update
 func (api *API) HostGroupsUpdate(hostGroups HostGroups) (err error) {
-	_, err = api.CallWithError("hostgroup.update", hostGroups)
-	return
+	return api.HostGroupsUpdateContext(context.Background(), hostGroups)
+}
+
+// HostGroupsUpdateContext is like HostGroupsUpdate but uses ctx for the request.
+func (api *API) HostGroupsUpdateContext(ctx context.Context, hostGroups HostGroups) (err error) {
+	return HostGroupResource.Update(ctx, api, hostGroups)
 }
 
 // HostGroupsDelete Wrapper for hostgroup.delete
 // Cleans GroupId in all hostGroups elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/delete
 func (api *API) HostGroupsDelete(hostGroups HostGroups) (err error) {
-	ids := make([]string, len(hostGroups))
-	for i, group := range hostGroups {
-		ids[i] = group.GroupID
-	}
+	return api.HostGroupsDeleteContext(context.Background(), hostGroups)
+}
 
-	err = api.HostGroupsDeleteByIds(ids)
-	if err == nil {
-		for i := range hostGroups {
-			hostGroups[i].GroupID = ""
-		}
-	}
-	return
+// HostGroupsDeleteContext is like HostGroupsDelete but uses ctx for the request.
+func (api *API) HostGroupsDeleteContext(ctx context.Context, hostGroups HostGroups) (err error) {
+	return HostGroupResource.Delete(ctx, api, hostGroups)
 }
 
 // HostGroupsDeleteByIds Wrapper for hostgroup.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/hostgroup/delete
 func (api *API) HostGroupsDeleteByIds(ids []string) (err error) {
-	response, err := api.CallWithError("hostgroup.delete", ids)
-	if err != nil {
-		return
-	}
-
-	result := response.Result.(map[string]interface{})
-	groupids := result["groupids"].([]interface{})
-	if len(ids) != len(groupids) {
-		err = &ExpectedMore{len(ids), len(groupids)}
-	}
-	return
+	return api.HostGroupsDeleteByIdsContext(context.Background(), ids)
+}
+
+// HostGroupsDeleteByIdsContext is like HostGroupsDeleteByIds but uses ctx for the request.
+func (api *API) HostGroupsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return HostGroupResource.DeleteByIDs(ctx, api, ids)
 }
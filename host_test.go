@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 
 	zapi "github.com/tpretz/go-zabbix-api"
@@ -61,6 +62,9 @@ func TestHosts(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	// no inventory mode was sent, the server default is read back
+	disabled := zapi.InventoryDisabled
+	host.RawInventoryMode, host.InventoryMode, host.InventoryModeSet = &disabled, disabled, true
 
 	host2, err := api.HostGetByHost(host.Host)
 	if err != nil {
@@ -96,3 +100,65 @@ func TestHosts(t *testing.T) {
 		t.Errorf("Bad hosts: %#v", hosts)
 	}
 }
+
+func TestHostsUpdatePartial(t *testing.T) {
+	api, sent := capturingAPI(t, "6.0.0")
+	if err := api.HostsUpdate(zapi.Hosts{{HostID: "1", Name: "x"}}); err != nil {
+		t.Fatal(err)
+	}
+	// an unset inventory mode must not switch hosts to manual inventory
+	if strings.Contains(string(sent["host.update"]), "inventory_mode") {
+		t.Errorf("inventory_mode sent: %s", sent["host.update"])
+	}
+}
+
+func TestHostInventoryMode(t *testing.T) {
+	api := getAPI(t)
+
+	group := CreateHostGroup(t)
+	defer DeleteHostGroup(group, t)
+
+	name := fmt.Sprintf("%s-%d", getHost(), rand.Int())
+	hosts := zapi.Hosts{{
+		Host:             name,
+		GroupIds:         zapi.HostGroupIDs{{group.GroupID}},
+		Interfaces:       zapi.HostInterfaces{{DNS: name, Port: "42", Type: zapi.Agent, UseIP: "0", Main: "1"}},
+		InventoryMode:    zapi.InventoryAutomatic,
+		InventoryModeSet: true,
+	}}
+	if err := api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	defer DeleteHost(&hosts[0], t)
+
+	host, err := api.HostGetByID(hosts[0].HostID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host.InventoryMode != zapi.InventoryAutomatic || !host.InventoryModeSet {
+		t.Errorf("Expected automatic inventory, got %d", host.InventoryMode)
+	}
+
+	// a partial update keeps the mode
+	if err = api.HostsUpdate(zapi.Hosts{{HostID: host.HostID, Host: host.Host, Name: "renamed"}}); err != nil {
+		t.Fatal(err)
+	}
+	if host, err = api.HostGetByID(host.HostID); err != nil {
+		t.Fatal(err)
+	}
+	if host.InventoryMode != zapi.InventoryAutomatic {
+		t.Errorf("Expected automatic inventory after update, got %d", host.InventoryMode)
+	}
+
+	// and a read host is updated with its mode
+	host.InventoryMode = zapi.InventoryManual
+	if err = api.HostsUpdate(zapi.Hosts{*host}); err != nil {
+		t.Fatal(err)
+	}
+	if host, err = api.HostGetByID(host.HostID); err != nil {
+		t.Fatal(err)
+	}
+	if host.InventoryMode != zapi.InventoryManual {
+		t.Errorf("Expected manual inventory, got %d", host.InventoryMode)
+	}
+}
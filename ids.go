@@ -0,0 +1,85 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// IDs is a list of object ids as returned by create, update and delete methods.
+// Depending on the Zabbix version and method they come back either as an array
+// or as an object keyed by position, both forms are accepted.
+type IDs []string
+
+// UnmarshalJSON accepts an array or an object of string or numeric ids.
+func (ids *IDs) UnmarshalJSON(b []byte) (err error) {
+	var list []json.RawMessage
+	if err = json.Unmarshal(b, &list); err == nil {
+		res := make(IDs, len(list))
+		for i, raw := range list {
+			if res[i], err = unmarshalID(raw); err != nil {
+				return
+			}
+		}
+		*ids = res
+		return
+	}
+
+	var obj map[string]json.RawMessage
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return fmt.Errorf("ids must be an array or an object, got %s", b)
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	// keep the server order, keys are positions
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA != nil || errB != nil {
+			return keys[i] < keys[j]
+		}
+		return a < b
+	})
+	res := make(IDs, len(keys))
+	for i, k := range keys {
+		if res[i], err = unmarshalID(obj[k]); err != nil {
+			return
+		}
+	}
+	*ids = res
+	return
+}
+
+func unmarshalID(raw json.RawMessage) (id string, err error) {
+	if err = json.Unmarshal(raw, &id); err == nil {
+		return
+	}
+	var n json.Number
+	if err = json.Unmarshal(raw, &n); err != nil {
+		return "", fmt.Errorf("invalid id %s", raw)
+	}
+	return n.String(), nil
+}
+
+// callIDs calls a create, update or delete method and returns the ids listed under key in its result.
+func (api *API) callIDs(ctx context.Context, method string, params interface{}, key string) (ids IDs, err error) {
+	var result map[string]json.RawMessage
+	err = api.CallWithErrorParseContext(ctx, method, params, &result)
+	if err != nil {
+		return
+	}
+
+	raw, ok := result[key]
+	if !ok {
+		return nil, &DecodeError{Object: method, Field: key, Err: errors.New("missing from result")}
+	}
+	if err = json.Unmarshal(raw, &ids); err != nil {
+		return nil, &DecodeError{Object: method, Field: key, Err: err}
+	}
+	return
+}
@@ -0,0 +1,69 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestIDsUnmarshal(t *testing.T) {
+	for in, want := range map[string]zapi.IDs{
+		`["1","2"]`:          {"1", "2"},
+		`[3,4]`:              {"3", "4"},
+		`{"1":"6","0":"5"}`:  {"5", "6"},
+		`{"10":"b","2":"a"}`: {"a", "b"},
+		`[]`:                 {},
+	} {
+		var ids zapi.IDs
+		if err := json.Unmarshal([]byte(in), &ids); err != nil {
+			t.Errorf("%s: %s", in, err)
+			continue
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("%s: got %v, want %v", in, ids, want)
+		}
+	}
+
+	var ids zapi.IDs
+	if err := json.Unmarshal([]byte(`"1"`), &ids); err == nil {
+		t.Error("Expected an error for a string")
+	}
+}
+
+func TestCreateDeleteIDs(t *testing.T) {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		switch method {
+		case "usermacro.create":
+			return map[string]interface{}{"hostmacroids": map[string]string{"0": "11", "1": "12"}}, nil
+		case "host.create":
+			return map[string]interface{}{"unexpected": true}, nil
+		}
+		return nil, &zapi.Error{Code: -32500, Message: "Application error.", Data: "No permissions to referred object or it does not exist!"}
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	macros := zapi.Macros{{HostID: "1", MacroName: "{$A}"}, {HostID: "1", MacroName: "{$B}"}}
+	if err = api.MacrosCreate(macros); err != nil {
+		t.Fatal(err)
+	}
+	if macros[0].MacroID != "11" || macros[1].MacroID != "12" || macros[0].HostID != "1" {
+		t.Errorf("Bad macros: %#v", macros)
+	}
+
+	if err = api.MacrosDeleteByIDs([]string{"11"}); err == nil {
+		t.Error("Expected an error")
+	}
+
+	err = api.HostsCreate(zapi.Hosts{{Host: "a"}})
+	if _, ok := err.(*zapi.DecodeError); !ok {
+		t.Errorf("Expected a decode error, got %#v", err)
+	}
+}
@@ -1,8 +1,8 @@
 package zabbix
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 )
 
 type (
@@ -184,13 +184,30 @@ type Preprocessor struct {
 // Items is an array of Item
 type Items []Item
 
-// ByKey Converts slice to map by key. Panics if there are duplicate keys.
-func (items Items) ByKey() (res map[string]Item) {
+// ItemResource describes item objects
+var ItemResource = &Resource[Item]{
+	Object:  "item",
+	IDField: "itemid",
+	Prepare: prepItems,
+	Decode:  itemHeadersUnmarshal,
+}
+
+// ItemPrototypeResource describes itemprototype objects
+var ItemPrototypeResource = &Resource[Item]{
+	Object:    "itemprototype",
+	IDField:   "itemid",
+	DeleteKey: "prototypeids",
+	Prepare:   prepItems,
+	Decode:    itemHeadersUnmarshal,
+}
+
+// ByKey Converts slice to map by key. Returns a DuplicateKeyError if there are duplicate keys.
+func (items Items) ByKey() (res map[string]Item, err error) {
 	res = make(map[string]Item, len(items))
 	for _, i := range items {
 		_, present := res[i.Key]
 		if present {
-			panic(fmt.Errorf("Duplicate key %s", i.Key))
+			return nil, DuplicateKeyError(i.Key)
 		}
 		res[i.Key] = i
 	}
@@ -200,71 +217,88 @@ func (items Items) ByKey() (res map[string]Item) {
 // ItemsGet Wrapper for item.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/get
 func (api *API) ItemsGet(params Params) (res Items, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("item.get", params, &res)
-	api.itemsHeadersUnmarshal(res)
-	return
+	return api.ItemsGetContext(context.Background(), params)
+}
+
+// ItemsGetContext is like ItemsGet but uses ctx for the request.
+func (api *API) ItemsGetContext(ctx context.Context, params Params) (res Items, err error) {
+	return ItemResource.Get(ctx, api, params)
+}
+
+// ItemsQuery Wrapper for item.get taking typed options instead of Params.
+func (api *API) ItemsQuery(q ItemQuery) (res Items, err error) {
+	return api.ItemsQueryContext(context.Background(), q)
+}
+
+// ItemsQueryContext is like ItemsQuery but uses ctx for the request.
+func (api *API) ItemsQueryContext(ctx context.Context, q ItemQuery) (res Items, err error) {
+	return ItemResource.Query(ctx, api, q)
+}
+
+// ItemsGetPages Wrapper for item.get returning the results page by page, see PageOptions.
+// fn is called for each page, an error returned by fn stops the pagination and is returned.
+func (api *API) ItemsGetPages(ctx context.Context, params Params, opts PageOptions, fn func(Items) error) error {
+	return ItemResource.GetPages(ctx, api, params, opts, func(items []Item) error {
+		return fn(items)
+	})
+}
+
+// ItemsGetStream is like ItemsGetPages but delivers items one by one on a channel.
+// The error channel is closed once all items are delivered, after receiving the error that stopped the stream if any.
+func (api *API) ItemsGetStream(ctx context.Context, params Params, opts PageOptions) (<-chan Item, <-chan error) {
+	return ItemResource.GetStream(ctx, api, params, opts)
 }
 func (api *API) ProtoItemsGet(params Params) (res Items, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("itemprototype.get", params, &res)
-	api.itemsHeadersUnmarshal(res)
-	return
+	return api.ProtoItemsGetContext(context.Background(), params)
 }
 
-func (api *API) itemsHeadersUnmarshal(item Items) {
-	for i := 0; i < len(item); i++ {
-		h := item[i]
+func (api *API) ProtoItemsGetContext(ctx context.Context, params Params) (res Items, err error) {
+	return ItemPrototypeResource.Get(ctx, api, params)
+}
 
-		if len(h.RawApplications) != 0 {
-			asStr := string(h.RawApplications)
-			if asStr != "[]" {
-				var applications Applications
-				err := json.Unmarshal(h.RawApplications, &applications)
-				if err != nil {
-					panic(err)
-				}
-				ids := []string{}
-				for _, a := range applications {
-					ids = append(ids, a.ApplicationID)
-				}
-				item[i].Applications = ids
+func itemHeadersUnmarshal(item *Item) *DecodeError {
+	if len(item.RawApplications) != 0 {
+		asStr := string(item.RawApplications)
+		if asStr != "[]" {
+			var applications Applications
+			if err := json.Unmarshal(item.RawApplications, &applications); err != nil {
+				return &DecodeError{"item", item.ItemID, "applications", err}
+			}
+			ids := []string{}
+			for _, a := range applications {
+				ids = append(ids, a.ApplicationID)
 			}
+			item.Applications = ids
 		}
+	}
 
-		item[i].Headers = HttpHeaders{}
+	item.Headers = HttpHeaders{}
 
-		if len(h.RawHeaders) == 0 {
-			continue
-		}
+	if len(item.RawHeaders) == 0 {
+		return nil
+	}
 
-		asStr := string(h.RawHeaders)
-		if asStr == "[]" {
-			continue
-		}
+	asStr := string(item.RawHeaders)
+	if asStr == "[]" {
+		return nil
+	}
 
-		out := HttpHeaders{}
-		err := json.Unmarshal(h.RawHeaders, &out)
-		if err != nil {
-			api.printf("got error during unmarshal %s", err)
-			panic(err)
-		}
-		item[i].Headers = out
+	out := HttpHeaders{}
+	if err := json.Unmarshal(item.RawHeaders, &out); err != nil {
+		return &DecodeError{"item", item.ItemID, "headers", err}
 	}
+	item.Headers = out
+	return nil
 }
 
-func prepItems(item Items) {
+func prepItems(item []Item) {
 	for i := 0; i < len(item); i++ {
 		h := item[i]
 
 		if h.Applications != nil {
 			text, _ := json.Marshal(h.Applications)
 			raw := json.RawMessage(text)
-			h.RawApplications = raw
+			item[i].RawApplications = raw
 		}
 
 		if h.Headers == nil {
@@ -277,178 +311,139 @@ func prepItems(item Items) {
 
 // ItemGetByID Gets item by Id only if there is exactly 1 matching host.
 func (api *API) ItemGetByID(id string) (res *Item, err error) {
-	items, err := api.ItemsGet(Params{"itemids": id})
-	if err != nil {
-		return
-	}
+	return api.ItemGetByIDContext(context.Background(), id)
+}
 
-	if len(items) != 1 {
-		e := ExpectedOneResult(len(items))
-		err = &e
-		return
-	}
-	res = &items[0]
-	return
+// ItemGetByIDContext is like ItemGetByID but uses ctx for the request.
+func (api *API) ItemGetByIDContext(ctx context.Context, id string) (res *Item, err error) {
+	return ItemResource.GetOne(ctx, api, id)
 }
 func (api *API) ProtoItemGetByID(id string) (res *Item, err error) {
-	items, err := api.ProtoItemsGet(Params{"itemids": id})
-	if err != nil {
-		return
-	}
+	return api.ProtoItemGetByIDContext(context.Background(), id)
+}
 
-	if len(items) != 1 {
-		e := ExpectedOneResult(len(items))
-		err = &e
-		return
-	}
-	res = &items[0]
-	return
+func (api *API) ProtoItemGetByIDContext(ctx context.Context, id string) (res *Item, err error) {
+	return ItemPrototypeResource.GetOne(ctx, api, id)
 }
 
 // ItemsGetByApplicationID Gets items by application Id.
 func (api *API) ItemsGetByApplicationID(id string) (res Items, err error) {
-	return api.ItemsGet(Params{"applicationids": id})
+	return api.ItemsGetByApplicationIDContext(context.Background(), id)
+}
+
+// ItemsGetByApplicationIDContext is like ItemsGetByApplicationID but uses ctx for the request.
+func (api *API) ItemsGetByApplicationIDContext(ctx context.Context, id string) (res Items, err error) {
+	return api.ItemsGetContext(ctx, Params{"applicationids": id})
 }
 func (api *API) ProtoItemsGetByApplicationID(id string) (res Items, err error) {
-	return api.ProtoItemsGet(Params{"applicationids": id})
+	return api.ProtoItemsGetByApplicationIDContext(context.Background(), id)
+}
+
+func (api *API) ProtoItemsGetByApplicationIDContext(ctx context.Context, id string) (res Items, err error) {
+	return api.ProtoItemsGetContext(ctx, Params{"applicationids": id})
 }
 
 // ItemsCreate Wrapper for item.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/create
 func (api *API) ItemsCreate(items Items) (err error) {
-	prepItems(items)
-	response, err := api.CallWithError("item.create", items)
-	if err != nil {
-		return
-	}
+	return api.ItemsCreateContext(context.Background(), items)
+}
 
-	result := response.Result.(map[string]interface{})
-	itemids := result["itemids"].([]interface{})
-	for i, id := range itemids {
-		items[i].ItemID = id.(string)
-	}
-	return
+// ItemsCreateContext is like ItemsCreate but uses ctx for the request.
+func (api *API) ItemsCreateContext(ctx context.Context, items Items) (err error) {
+	return ItemResource.Create(ctx, api, items)
 }
 func (api *API) ProtoItemsCreate(items Items) (err error) {
-	prepItems(items)
-	response, err := api.CallWithError("itemprototype.create", items)
-	if err != nil {
-		return
-	}
+	return api.ProtoItemsCreateContext(context.Background(), items)
+}
 
-	result := response.Result.(map[string]interface{})
-	itemids := result["itemids"].([]interface{})
-	for i, id := range itemids {
-		items[i].ItemID = id.(string)
-	}
-	return
+func (api *API) ProtoItemsCreateContext(ctx context.Context, items Items) (err error) {
+	return ItemPrototypeResource.Create(ctx, api, items)
 }
 
 // ItemsUpdate Wrapper for item.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/update
 func (api *API) ItemsUpdate(items Items) (err error) {
-	prepItems(items)
-	_, err = api.CallWithError("item.update", items)
-	return
+	return api.ItemsUpdateContext(context.Background(), items)
+}
+
+// ItemsUpdateContext is like ItemsUpdate but uses ctx for the request.
+func (api *API) ItemsUpdateContext(ctx context.Context, items Items) (err error) {
+	return ItemResource.Update(ctx, api, items)
 }
 func (api *API) ProtoItemsUpdate(items Items) (err error) {
-	prepItems(items)
-	_, err = api.CallWithError("itemprototype.update", items)
-	return
+	return api.ProtoItemsUpdateContext(context.Background(), items)
+}
+
+func (api *API) ProtoItemsUpdateContext(ctx context.Context, items Items) (err error) {
+	return ItemPrototypeResource.Update(ctx, api, items)
 }
 
 // ItemsDelete Wrapper for item.delete
 // Cleans ItemId in all items elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/delete
 func (api *API) ItemsDelete(items Items) (err error) {
-	ids := make([]string, len(items))
-	for i, item := range items {
-		ids[i] = item.ItemID
-	}
+	return api.ItemsDeleteContext(context.Background(), items)
+}
 
-	err = api.ItemsDeleteByIds(ids)
-	if err == nil {
-		for i := range items {
-			items[i].ItemID = ""
-		}
-	}
-	return
+// ItemsDeleteContext is like ItemsDelete but uses ctx for the request.
+func (api *API) ItemsDeleteContext(ctx context.Context, items Items) (err error) {
+	return ItemResource.Delete(ctx, api, items)
 }
 func (api *API) ProtoItemsDelete(items Items) (err error) {
-	ids := make([]string, len(items))
-	for i, item := range items {
-		ids[i] = item.ItemID
-	}
+	return api.ProtoItemsDeleteContext(context.Background(), items)
+}
 
-	err = api.ProtoItemsDeleteByIds(ids)
-	if err == nil {
-		for i := range items {
-			items[i].ItemID = ""
-		}
-	}
-	return
+func (api *API) ProtoItemsDeleteContext(ctx context.Context, items Items) (err error) {
+	return ItemPrototypeResource.Delete(ctx, api, items)
 }
 
 // ItemsDeleteByIds Wrapper for item.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/delete
 func (api *API) ItemsDeleteByIds(ids []string) (err error) {
-	deleteIds, err := api.ItemsDeleteIDs(ids)
-	if err != nil {
-		return
-	}
-	l := len(deleteIds)
-	if len(ids) != l {
-		err = &ExpectedMore{len(ids), l}
-	}
-	return
+	return api.ItemsDeleteByIdsContext(context.Background(), ids)
+}
+
+// ItemsDeleteByIdsContext is like ItemsDeleteByIds but uses ctx for the request.
+func (api *API) ItemsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return ItemResource.DeleteByIDs(ctx, api, ids)
 }
 func (api *API) ProtoItemsDeleteByIds(ids []string) (err error) {
-	deleteIds, err := api.ProtoItemsDeleteIDs(ids)
-	if err != nil {
-		return
-	}
-	l := len(deleteIds)
-	if len(ids) != l {
-		err = &ExpectedMore{len(ids), l}
-	}
-	return
+	return api.ProtoItemsDeleteByIdsContext(context.Background(), ids)
+}
+
+func (api *API) ProtoItemsDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return ItemPrototypeResource.DeleteByIDs(ctx, api, ids)
 }
 
 // ItemsDeleteIDs Wrapper for item.delete
 // Delete the item and return the id of the deleted item
 func (api *API) ItemsDeleteIDs(ids []string) (itemids []interface{}, err error) {
-	response, err := api.CallWithError("item.delete", ids)
+	return api.ItemsDeleteIDsContext(context.Background(), ids)
+}
+
+// ItemsDeleteIDsContext is like ItemsDeleteIDs but uses ctx for the request.
+func (api *API) ItemsDeleteIDsContext(ctx context.Context, ids []string) (itemids []interface{}, err error) {
+	deleted, err := api.callIDs(ctx, "item.delete", ids, "itemids")
 	if err != nil {
 		return
 	}
-
-	result := response.Result.(map[string]interface{})
-	itemids1, ok := result["itemids"].([]interface{})
-	if !ok {
-		itemids2 := result["itemids"].(map[string]interface{})
-		for _, id := range itemids2 {
-			itemids = append(itemids, id)
-		}
-	} else {
-		itemids = itemids1
+	for _, id := range deleted {
+		itemids = append(itemids, id)
 	}
 	return
 }
 func (api *API) ProtoItemsDeleteIDs(ids []string) (itemids []interface{}, err error) {
-	response, err := api.CallWithError("itemprototype.delete", ids)
+	return api.ProtoItemsDeleteIDsContext(context.Background(), ids)
+}
+
+func (api *API) ProtoItemsDeleteIDsContext(ctx context.Context, ids []string) (itemids []interface{}, err error) {
+	deleted, err := api.callIDs(ctx, "itemprototype.delete", ids, "prototypeids")
 	if err != nil {
 		return
 	}
-
-	result := response.Result.(map[string]interface{})
-	itemids1, ok := result["prototypeids"].([]interface{})
-	if !ok {
-		itemids2 := result["prototypeids"].(map[string]interface{})
-		for _, id := range itemids2 {
-			itemids = append(itemids, id)
-		}
-	} else {
-		itemids = itemids1
+	for _, id := range deleted {
+		itemids = append(itemids, id)
 	}
 	return
 }
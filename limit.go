@@ -0,0 +1,165 @@
+package zabbix
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit caps the rate and the concurrency of calls.
+// The zero value does not limit anything.
+type Limit struct {
+	// Rate is the number of calls per second allowed on average, unlimited if zero
+	Rate float64
+	// Burst is the number of calls that may be sent at once when the rate allows it, 1 if zero
+	Burst int
+	// MaxInFlight caps the number of calls waiting for a response, unlimited if zero
+	MaxInFlight int
+}
+
+// limiter enforces a Limit with a token bucket and a semaphore
+type limiter struct {
+	// pattern is the Config.MethodLimits key, limiters are always acquired in its order
+	pattern string
+	rate    float64
+	burst   float64
+	sem     chan struct{}
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(l Limit) *limiter {
+	if l.Rate <= 0 && l.MaxInFlight <= 0 {
+		return nil
+	}
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	lim := &limiter{rate: l.Rate, burst: burst, tokens: burst}
+	if l.MaxInFlight > 0 {
+		lim.sem = make(chan struct{}, l.MaxInFlight)
+	}
+	return lim
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (l *limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token
+func (l *limiter) cancel() {
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+// acquire waits for the rate and a free slot, release must be called once the call is done.
+func (l *limiter) acquire(ctx context.Context) (release func(), err error) {
+	if l.rate > 0 {
+		if wait := l.reserve(); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				l.cancel()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+		}
+	}
+	if l.sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return func() { <-l.sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// limiters returns the limiters that apply to methods: Config.Limit and
+// the most specific Config.MethodLimits entry of each method.
+func (api *API) limiters(methods ...string) (res []*limiter) {
+	api.limitOnce.Do(func() {
+		api.limit = newLimiter(api.Config.Limit)
+		api.methodLimits = make(map[string]*limiter, len(api.Config.MethodLimits))
+		for pattern, l := range api.Config.MethodLimits {
+			if lim := newLimiter(l); lim != nil {
+				lim.pattern = strings.ToLower(pattern)
+				api.methodLimits[lim.pattern] = lim
+			}
+		}
+	})
+
+	seen := map[*limiter]bool{}
+	for _, method := range methods {
+		lim := api.methodLimit(strings.ToLower(method))
+		if lim != nil && !seen[lim] {
+			seen[lim] = true
+			res = append(res, lim)
+		}
+	}
+	// a fixed order keeps concurrent batches from holding each other's slots,
+	// and the global limit comes last so calls queued on a method limit do not hold it
+	sort.Slice(res, func(i, j int) bool { return res[i].pattern < res[j].pattern })
+	if api.limit != nil {
+		res = append(res, api.limit)
+	}
+	return
+}
+
+// methodLimit looks up "object.verb", then "object.*", then "*.verb"
+func (api *API) methodLimit(method string) *limiter {
+	if lim, ok := api.methodLimits[method]; ok {
+		return lim
+	}
+	object, verb := method, ""
+	if i := strings.LastIndex(method, "."); i >= 0 {
+		object, verb = method[:i], method[i+1:]
+	}
+	if lim, ok := api.methodLimits[object+".*"]; ok {
+		return lim
+	}
+	return api.methodLimits["*."+verb]
+}
+
+// wait acquires all limiters applying to methods, release must be called once the calls are done.
+func (api *API) wait(ctx context.Context, methods ...string) (release func(), err error) {
+	var releases []func()
+	release = func() {
+		for _, r := range releases {
+			r()
+		}
+	}
+	for _, lim := range api.limiters(methods...) {
+		r, err := lim.acquire(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, r)
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+// concurrencyServer answers after a delay and tracks the highest number of concurrent calls per method
+func concurrencyServer(t *testing.T, delay time.Duration) (*zapi.API, map[string]*int32) {
+	var mu sync.Mutex
+	current, peak := map[string]*int32{}, map[string]*int32{}
+	for _, m := range []string{"host.get", "host.create", "all"} {
+		current[m], peak[m] = new(int32), new(int32)
+	}
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		mu.Lock()
+		for _, m := range []string{method, "all"} {
+			if n := atomic.AddInt32(current[m], 1); n > *peak[m] {
+				*peak[m] = n
+			}
+		}
+		mu.Unlock()
+		time.Sleep(delay)
+		atomic.AddInt32(current[method], -1)
+		atomic.AddInt32(current["all"], -1)
+		if method == "host.create" {
+			return map[string][]string{"hostids": {"1"}}, nil
+		}
+		return []interface{}{}, nil
+	})
+	t.Cleanup(srv.Close)
+
+	api, err := zapi.NewAPI(zapi.Config{
+		Url:          srv.URL,
+		Limit:        zapi.Limit{MaxInFlight: 3},
+		MethodLimits: map[string]zapi.Limit{"*.create": {MaxInFlight: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api, peak
+}
+
+func TestMaxInFlight(t *testing.T) {
+	api, peak := concurrencyServer(t, 20*time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, err := api.HostsGet(zapi.Params{}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := api.HostsCreate(zapi.Hosts{{Host: "host"}}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if *peak["host.create"] != 1 {
+		t.Errorf("Expected at most 1 concurrent create, got %d", *peak["host.create"])
+	}
+	if *peak["all"] > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, got %d", *peak["all"])
+	}
+	if *peak["host.get"] < 2 {
+		t.Errorf("Expected concurrent gets, got %d", *peak["host.get"])
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		return []interface{}{}, nil
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{
+		Url:          srv.URL,
+		MethodLimits: map[string]zapi.Limit{"host.get": {Rate: 50, Burst: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		if _, err = api.HostsGet(zapi.Params{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 2 calls from the burst, then 5 calls at 20ms intervals
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Expected calls to be limited, took %s", elapsed)
+	}
+
+	// other methods are not limited
+	start = time.Now()
+	for i := 0; i < 7; i++ {
+		if _, err = api.HostGroupsGet(zapi.Params{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 90*time.Millisecond {
+		t.Errorf("Expected hostgroup.get not to be limited, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	api.HostsGetContext(ctx, zapi.Params{})
+	if _, err = api.HostsGetContext(ctx, zapi.Params{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded while waiting, got %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package zabbix
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+)
 
 type (
 	LLDEvalType     string
@@ -105,32 +108,36 @@ type LLDRule struct {
 // Items is an array of Item
 type LLDRules []LLDRule
 
-func (api *API) lldsHeadersUnmarshal(item LLDRules) {
-	for i := 0; i < len(item); i++ {
-		h := item[i]
+// LLDResource describes discoveryrule objects
+var LLDResource = &Resource[LLDRule]{
+	Object:    "discoveryrule",
+	IDField:   "itemid",
+	DeleteKey: "ruleids",
+	Prepare:   func(rules []LLDRule) { prepLLDs(rules) },
+	Decode:    lldHeadersUnmarshal,
+}
 
-		item[i].Headers = HttpHeaders{}
+func lldHeadersUnmarshal(item *LLDRule) *DecodeError {
+	item.Headers = HttpHeaders{}
 
-		if len(h.RawHeaders) == 0 {
-			continue
-		}
+	if len(item.RawHeaders) == 0 {
+		return nil
+	}
 
-		asStr := string(h.RawHeaders)
-		if asStr == "[]" {
-			continue
-		}
+	asStr := string(item.RawHeaders)
+	if asStr == "[]" {
+		return nil
+	}
 
-		out := HttpHeaders{}
-		err := json.Unmarshal(h.RawHeaders, &out)
-		if err != nil {
-			api.printf("got error during unmarshal %s", err)
-			panic(err)
-		}
-		item[i].Headers = out
+	out := HttpHeaders{}
+	if err := json.Unmarshal(item.RawHeaders, &out); err != nil {
+		return &DecodeError{"discoveryrule", item.ItemID, "headers", err}
 	}
+	item.Headers = out
+	return nil
 }
 
-func prepLLDs(item LLDRules) {
+func prepLLDs(item []LLDRule) {
 	for i := 0; i < len(item); i++ {
 		h := item[i]
 
@@ -145,104 +152,83 @@ func prepLLDs(item LLDRules) {
 // ItemsGet Wrapper for item.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/get
 func (api *API) LLDsGet(params Params) (res LLDRules, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("discoveryrule.get", params, &res)
-	api.lldsHeadersUnmarshal(res)
-	return
+	return api.LLDsGetContext(context.Background(), params)
+}
+
+// LLDsGetContext is like LLDsGet but uses ctx for the request.
+func (api *API) LLDsGetContext(ctx context.Context, params Params) (res LLDRules, err error) {
+	return LLDResource.Get(ctx, api, params)
 }
 
 // ItemGetByID Gets item by Id only if there is exactly 1 matching host.
 func (api *API) LLDGetByID(id string) (res *LLDRule, err error) {
-	items, err := api.LLDsGet(Params{"itemids": id})
-	if err != nil {
-		return
-	}
+	return api.LLDGetByIDContext(context.Background(), id)
+}
 
-	if len(items) != 1 {
-		e := ExpectedOneResult(len(items))
-		err = &e
-		return
-	}
-	res = &items[0]
-	return
+// LLDGetByIDContext is like LLDGetByID but uses ctx for the request.
+func (api *API) LLDGetByIDContext(ctx context.Context, id string) (res *LLDRule, err error) {
+	return LLDResource.GetOne(ctx, api, id)
 }
 
 // ItemsCreate Wrapper for item.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/create
 func (api *API) LLDsCreate(items LLDRules) (err error) {
-	prepLLDs(items)
-	response, err := api.CallWithError("discoveryrule.create", items)
-	if err != nil {
-		return
-	}
+	return api.LLDsCreateContext(context.Background(), items)
+}
 
-	result := response.Result.(map[string]interface{})
-	itemids := result["itemids"].([]interface{})
-	for i, id := range itemids {
-		items[i].ItemID = id.(string)
-	}
-	return
+// LLDsCreateContext is like LLDsCreate but uses ctx for the request.
+func (api *API) LLDsCreateContext(ctx context.Context, items LLDRules) (err error) {
+	return LLDResource.Create(ctx, api, items)
 }
 
 // ItemsUpdate Wrapper for item.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/update
 func (api *API) LLDsUpdate(items LLDRules) (err error) {
-	prepLLDs(items)
-	_, err = api.CallWithError("discoveryrule.update", items)
-	return
+	return api.LLDsUpdateContext(context.Background(), items)
+}
+
+// LLDsUpdateContext is like LLDsUpdate but uses ctx for the request.
+func (api *API) LLDsUpdateContext(ctx context.Context, items LLDRules) (err error) {
+	return LLDResource.Update(ctx, api, items)
 }
 
 // ItemsDelete Wrapper for item.delete
 // Cleans ItemId in all items elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/delete
 func (api *API) LLDsDelete(items LLDRules) (err error) {
-	ids := make([]string, len(items))
-	for i, item := range items {
-		ids[i] = item.ItemID
-	}
+	return api.LLDsDeleteContext(context.Background(), items)
+}
 
-	err = api.LLDDeleteByIds(ids)
-	if err == nil {
-		for i := range items {
-			items[i].ItemID = ""
-		}
-	}
-	return
+// LLDsDeleteContext is like LLDsDelete but uses ctx for the request.
+func (api *API) LLDsDeleteContext(ctx context.Context, items LLDRules) (err error) {
+	return LLDResource.Delete(ctx, api, items)
 }
 
 // ItemsDeleteByIds Wrapper for item.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/item/delete
 func (api *API) LLDDeleteByIds(ids []string) (err error) {
-	deleteIds, err := api.LLDDeleteIDs(ids)
-	if err != nil {
-		return
-	}
-	l := len(deleteIds)
-	if len(ids) != l {
-		err = &ExpectedMore{len(ids), l}
-	}
-	return
+	return api.LLDDeleteByIdsContext(context.Background(), ids)
+}
+
+// LLDDeleteByIdsContext is like LLDDeleteByIds but uses ctx for the request.
+func (api *API) LLDDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return LLDResource.DeleteByIDs(ctx, api, ids)
 }
 
 // ItemsDeleteIDs Wrapper for item.delete
 // Delete the item and return the id of the deleted item
 func (api *API) LLDDeleteIDs(ids []string) (itemids []interface{}, err error) {
-	response, err := api.CallWithError("discoveryrule.delete", ids)
+	return api.LLDDeleteIDsContext(context.Background(), ids)
+}
+
+// LLDDeleteIDsContext is like LLDDeleteIDs but uses ctx for the request.
+func (api *API) LLDDeleteIDsContext(ctx context.Context, ids []string) (itemids []interface{}, err error) {
+	deleted, err := api.callIDs(ctx, "discoveryrule.delete", ids, "ruleids")
 	if err != nil {
 		return
 	}
-
-	result := response.Result.(map[string]interface{})
-	itemids1, ok := result["ruleids"].([]interface{})
-	if !ok {
-		itemids2 := result["ruleids"].(map[string]interface{})
-		for _, id := range itemids2 {
-			itemids = append(itemids, id)
-		}
-	} else {
-		itemids = itemids1
+	for _, id := range deleted {
+		itemids = append(itemids, id)
 	}
 	return
 }
@@ -0,0 +1,129 @@
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LogLevel selects how much of each HTTP exchange is logged.
+type LogLevel int
+
+const (
+	// LogFull logs request and response bodies with secrets redacted, this is the default
+	LogFull LogLevel = iota
+	// LogMetadata logs method, request id, HTTP status, size and duration only
+	LogMetadata
+	// LogOff disables request logging, events such as retries and relogins are still logged
+	LogOff
+)
+
+// logging reports whether a logger is configured
+func (api *API) logging() bool {
+	return api.Logger != nil || api.Config.Slog != nil
+}
+
+func (api *API) printf(format string, v ...interface{}) {
+	if api.Logger != nil {
+		api.Logger.Printf(format, v...)
+	}
+	if api.Config.Slog != nil {
+		api.Config.Slog.Warn(fmt.Sprintf(format, v...))
+	}
+}
+
+// logExchange logs a posted body and its outcome according to Config.LogLevel.
+// status is 0 when no response was received.
+func (api *API) logExchange(url string, body []byte, status int, response []byte, elapsed time.Duration, err error) {
+	if api.Config.LogLevel == LogOff || !api.logging() {
+		return
+	}
+	method, id := describeRequest(body)
+
+	if api.Logger != nil {
+		switch {
+		case api.Config.LogLevel == LogMetadata && err != nil:
+			api.Logger.Printf("Request %s (id %s) failed after %s: %s", method, id, elapsed, err)
+		case api.Config.LogLevel == LogMetadata:
+			api.Logger.Printf("Request %s (id %s): HTTP %d, %d bytes in %s", method, id, status, len(response), elapsed)
+		default:
+			api.Logger.Printf("Request (POST): %s", redactJSON(body))
+			if status == 0 {
+				api.Logger.Printf("Error   : %s", err)
+			} else {
+				api.Logger.Printf("Response (%d): %s", status, redactResponse(method, response))
+			}
+		}
+	}
+
+	if l := api.Config.Slog; l != nil {
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("id", id),
+			slog.Duration("duration", elapsed),
+		}
+		if len(api.endpoints) > 1 {
+			attrs = append(attrs, slog.String("endpoint", url))
+		}
+		if status != 0 {
+			attrs = append(attrs, slog.Int("status", status), slog.Int("bytes", len(response)))
+		}
+		if api.Config.LogLevel == LogFull {
+			attrs = append(attrs, slog.String("request", string(redactJSON(body))))
+			if status != 0 {
+				attrs = append(attrs, slog.String("response", string(redactResponse(method, response))))
+			}
+		}
+		level := slog.LevelDebug
+		if err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		l.LogAttrs(context.Background(), level, "JSON-RPC request", attrs...)
+	}
+}
+
+// describeRequest extracts the methods and ids of a single or batch request for logging
+func describeRequest(body []byte) (method, id string) {
+	type call struct {
+		Method string `json:"method"`
+		ID     int32  `json:"id"`
+	}
+	var calls []call
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		json.Unmarshal(trimmed, &calls)
+	} else {
+		var c call
+		json.Unmarshal(trimmed, &c)
+		calls = []call{c}
+	}
+
+	methods, ids := make([]string, len(calls)), make([]string, len(calls))
+	for i, c := range calls {
+		methods[i], ids[i] = c.Method, fmt.Sprint(c.ID)
+	}
+	return strings.Join(methods, ","), strings.Join(ids, ",")
+}
+
+// redactResponse redacts a response body, including the session id returned by user.login
+func redactResponse(method string, response []byte) []byte {
+	if !strings.EqualFold(method, "user.login") {
+		return redactJSON(response)
+	}
+	var res map[string]json.RawMessage
+	if json.Unmarshal(response, &res) != nil {
+		return response
+	}
+	if _, ok := res["result"]; ok {
+		res["result"], _ = json.Marshal(redacted)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		return response
+	}
+	return b
+}
@@ -0,0 +1,142 @@
+package zabbix_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+// logSecrets logs in and sends a few objects holding secrets, returning the secrets
+func logSecrets(t *testing.T, c zapi.Config) []string {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+
+	c.Url = srv.URL
+	api, err := zapi.NewAPI(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := zapi.HostGroups{{Name: "secrets"}}
+	if err = api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	hosts := zapi.Hosts{{
+		Host:     "snmp",
+		GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}},
+		Interfaces: zapi.HostInterfaces{{
+			IP: "127.0.0.1", Main: "1", Port: "161", Type: zapi.SNMP, UseIP: "1",
+			Details: &zapi.HostInterfaceDetail{Version: "3", SecurityName: "monitor", AuthPassphrase: "auth-secret", PrivPassphrase: "priv-secret"},
+		}},
+		// typed macros have no type, their values may be secret
+		UserMacros: zapi.Macros{{MacroName: "{$API_KEY}", Value: "typed-macro-secret"}},
+	}}
+	if err = api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	items := zapi.Items{
+		{HostID: hosts[0].HostID, Key: "web", Name: "web", Type: zapi.HTTPAgent, Url: "http://localhost", Password: "http-secret",
+			Headers: zapi.HttpHeaders{"Authorization": "Bearer header-secret"}},
+		{HostID: hosts[0].HostID, Key: "uptime.v2", Name: "uptime v2", Type: zapi.SNMPv2Agent, SNMPOid: "1.3.6.1.2.1.1.3.0", SNMPCommunity: "community-secret"},
+		{HostID: hosts[0].HostID, Key: "uptime.v3", Name: "uptime v3", Type: zapi.SNMPv3Agent, SNMPOid: "1.3.6.1.2.1.1.3.0",
+			SNMPv3AuthPassphrase: "item-auth-secret", SNMPv3PrivPasshrase: "item-priv-secret"},
+	}
+	if err = api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+	_, err = api.CallWithError("item.update", zapi.Params{"itemid": items[0].ItemID, "ssl_key_password": "key-secret", "http_password": "plain-secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = api.CallWithError("usermacro.create", []zapi.Params{
+		{"hostid": hosts[0].HostID, "macro": "{$DB_PASSWORD}", "value": "macro-secret", "type": "1"},
+		{"hostid": hosts[0].HostID, "macro": "{$DB_PORT}", "value": "5432", "type": "0"},
+		{"hostid": hosts[0].HostID, "macro": "{$DB_KEY}", "value": "untyped-macro-secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []string{auth, zabbixtest.DefaultPassword, "auth-secret", "priv-secret", "http-secret", "macro-secret",
+		"community-secret", "item-auth-secret", "item-priv-secret", "typed-macro-secret", "Bearer header-secret", "untyped-macro-secret",
+		"key-secret", "plain-secret"}
+}
+
+func TestLogRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	secrets := logSecrets(t, zapi.Config{Log: log.New(&buf, "", 0)})
+
+	out := buf.String()
+	for _, secret := range secrets {
+		if strings.Contains(out, `"`+secret+`"`) {
+			t.Errorf("Secret %q logged", secret)
+		}
+	}
+	for _, expected := range []string{`"method":"host.create"`, `"securityname":"monitor"`, `"privpassphrase":"[REDACTED]"`, `"value":"[REDACTED]"`, `"snmpv3_authpassphrase":"[REDACTED]"`,
+		`"headers":"[REDACTED]"`, `"value":"5432"`} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("Expected %s in log", expected)
+		}
+	}
+}
+
+func TestLogMetadata(t *testing.T) {
+	var buf bytes.Buffer
+	logSecrets(t, zapi.Config{Log: log.New(&buf, "", 0), LogLevel: zapi.LogMetadata})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("Expected 7 lines, got %q", lines)
+	}
+	if !strings.HasPrefix(lines[1], "Request user.login (id 2): HTTP 200, ") {
+		t.Errorf("Unexpected line %q", lines[1])
+	}
+	if strings.Contains(buf.String(), "{") {
+		t.Errorf("Bodies logged in metadata mode: %s", buf.String())
+	}
+
+	buf.Reset()
+	logSecrets(t, zapi.Config{Log: log.New(&buf, "", 0), LogLevel: zapi.LogOff})
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log, got %s", buf.String())
+	}
+}
+
+func TestSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	secrets := logSecrets(t, zapi.Config{Slog: l})
+
+	for _, secret := range secrets {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("Secret %q logged", secret)
+		}
+	}
+
+	var records []map[string]interface{}
+	d := json.NewDecoder(&buf)
+	for d.More() {
+		var r map[string]interface{}
+		if err := d.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 7 {
+		t.Fatalf("Expected 7 records, got %d", len(records))
+	}
+	r := records[2]
+	if r["level"] != "DEBUG" || r["method"] != "hostgroup.create" || r["status"] != 200.0 || r["request"] == nil {
+		t.Errorf("Unexpected record %v", r)
+	}
+}
@@ -1,9 +1,11 @@
 package zabbix
 
+import "context"
+
 // Macro represent Zabbix User MAcro object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/object
 type Macro struct {
-	MacroID   string `json:"hostmacroids,omitempty"`
+	MacroID   string `json:"hostmacroid,omitempty"`
 	HostID    string `json:"hostid,omitempty"`
 	MacroName string `json:"macro"`
 	Value     string `json:"value"`
@@ -12,82 +14,74 @@ type Macro struct {
 // Macros is an array of Macro
 type Macros []Macro
 
+// MacroResource describes usermacro objects
+var MacroResource = &Resource[Macro]{
+	Object:  "usermacro",
+	IDField: "hostmacroid",
+}
+
 // MacrosGet Wrapper for usermacro.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/get
 func (api *API) MacrosGet(params Params) (res Macros, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("usermacro.get", params, &res)
-	return
+	return api.MacrosGetContext(context.Background(), params)
+}
+
+// MacrosGetContext is like MacrosGet but uses ctx for the request.
+func (api *API) MacrosGetContext(ctx context.Context, params Params) (res Macros, err error) {
+	return MacroResource.Get(ctx, api, params)
 }
 
 // MacroGetByID Get macro by macro ID if there is exactly 1 matching macro
 func (api *API) MacroGetByID(id string) (res *Macro, err error) {
-	triggers, err := api.MacrosGet(Params{"hostmacroids": id})
-	if err != nil {
-		return
-	}
-
-	if len(triggers) == 1 {
-		res = &triggers[0]
-	} else {
-		e := ExpectedOneResult(len(triggers))
-		err = &e
-	}
-	return
+	return api.MacroGetByIDContext(context.Background(), id)
+}
+
+// MacroGetByIDContext is like MacroGetByID but uses ctx for the request.
+func (api *API) MacroGetByIDContext(ctx context.Context, id string) (res *Macro, err error) {
+	return MacroResource.GetOne(ctx, api, id)
 }
 
 // MacrosCreate Wrapper for usermacro.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/create
 func (api *API) MacrosCreate(macros Macros) error {
-	response, err := api.CallWithError("usermacro.create", macros)
-	if err != nil {
-		return err
-	}
-
-	result := response.Result.(map[string]interface{})
-	macroids := result["hostmacroids"].([]interface{})
-	for i, id := range macroids {
-		macros[i].HostID = id.(string)
-	}
-	return nil
+	return api.MacrosCreateContext(context.Background(), macros)
+}
+
+// MacrosCreateContext is like MacrosCreate but uses ctx for the request.
+func (api *API) MacrosCreateContext(ctx context.Context, macros Macros) error {
+	return MacroResource.Create(ctx, api, macros)
 }
 
 // MacrosUpdate Wrapper for usermacro.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/update
 func (api *API) MacrosUpdate(macros Macros) (err error) {
-	_, err = api.CallWithError("usermacro.create", macros)
-	return
+	return api.MacrosUpdateContext(context.Background(), macros)
+}
+
+// MacrosUpdateContext is like MacrosUpdate but uses ctx for the request.
+func (api *API) MacrosUpdateContext(ctx context.Context, macros Macros) (err error) {
+	return MacroResource.Update(ctx, api, macros)
 }
 
 // MacrosDeleteByIDs Wrapper for usermacro.delete
 // Cleans MacroId in all macro elements if call succeed.
-//https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/delete
+// https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/delete
 func (api *API) MacrosDeleteByIDs(ids []string) (err error) {
-	response, err := api.CallWithError("usermacro.delete", ids)
-
-	result := response.Result.(map[string]interface{})
-	hostmacroids := result["hostmacroids"].([]interface{})
-	if len(ids) != len(hostmacroids) {
-		err = &ExpectedMore{len(ids), len(hostmacroids)}
-	}
-	return
+	return api.MacrosDeleteByIDsContext(context.Background(), ids)
+}
+
+// MacrosDeleteByIDsContext is like MacrosDeleteByIDs but uses ctx for the request.
+func (api *API) MacrosDeleteByIDsContext(ctx context.Context, ids []string) (err error) {
+	return MacroResource.DeleteByIDs(ctx, api, ids)
 }
 
 // MacrosDelete Wrapper for usermacro.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/usermacro/delete
 func (api *API) MacrosDelete(macros Macros) (err error) {
-	ids := make([]string, len(macros))
-	for i, macro := range macros {
-		ids[i] = macro.MacroID
-	}
-
-	err = api.MacrosDeleteByIDs(ids)
-	if err == nil {
-		for i := range macros {
-			macros[i].MacroID = ""
-		}
-	}
-	return
+	return api.MacrosDeleteContext(context.Background(), macros)
+}
+
+// MacrosDeleteContext is like MacrosDelete but uses ctx for the request.
+func (api *API) MacrosDeleteContext(ctx context.Context, macros Macros) (err error) {
+	return MacroResource.Delete(ctx, api, macros)
 }
@@ -0,0 +1,31 @@
+package zabbix
+
+import (
+	"time"
+)
+
+// Metrics receives instrumentation events of an API, set it in Config.Metrics.
+// Implementations must be safe for concurrent use.
+// Package github.com/tpretz/go-zabbix-api/zabbixprom exports them to Prometheus.
+type Metrics interface {
+	// CallStarted is called when a call is sent, CallDone when it returns
+	CallStarted(method string)
+	// CallDone receives the duration and outcome of a call,
+	// err is an *Error for API errors and nil on success
+	CallDone(method string, elapsed time.Duration, err error)
+	// HTTPResponse is called for each HTTP response received, retries included
+	HTTPResponse(status int)
+}
+
+// observe reports a call to Config.Metrics and returns the function to call once it is done
+func (api *API) observe(method string) func(err error) {
+	m := api.Config.Metrics
+	if m == nil {
+		return func(error) {}
+	}
+	start := time.Now()
+	m.CallStarted(method)
+	return func(err error) {
+		m.CallDone(method, time.Since(start), err)
+	}
+}
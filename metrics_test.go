@@ -0,0 +1,79 @@
+package zabbix_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+type recordedMetrics struct {
+	mu       sync.Mutex
+	inFlight int
+	events   []string
+}
+
+func (m *recordedMetrics) CallStarted(method string) {
+	m.mu.Lock()
+	m.inFlight++
+	m.mu.Unlock()
+}
+
+func (m *recordedMetrics) CallDone(method string, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	var e *zapi.Error
+	switch {
+	case err == nil:
+		m.events = append(m.events, method+" ok")
+	case errors.As(err, &e):
+		m.events = append(m.events, fmt.Sprintf("%s %d", method, e.Code))
+	default:
+		m.events = append(m.events, method+" failed")
+	}
+}
+
+func (m *recordedMetrics) HTTPResponse(status int) {
+	m.mu.Lock()
+	m.events = append(m.events, fmt.Sprintf("HTTP %d", status))
+	m.mu.Unlock()
+}
+
+func TestMetrics(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+
+	m := &recordedMetrics{}
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Metrics: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, "wrong"); err == nil {
+		t.Fatal("Expected login error")
+	}
+
+	b := api.NewBatch()
+	b.Add("apiinfo.version", zapi.Params{}, nil)
+	b.Add("host.nope", zapi.Params{}, nil)
+	if err = b.Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"HTTP 200", "APIInfo.version ok",
+		"HTTP 200", "user.login -32500",
+		"HTTP 200", "apiinfo.version ok", "host.nope -32602",
+	}
+	if !reflect.DeepEqual(m.events, expected) {
+		t.Errorf("Expected %v, got %v", expected, m.events)
+	}
+	if m.inFlight != 0 {
+		t.Errorf("Expected no call in flight, got %d", m.inFlight)
+	}
+}
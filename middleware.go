@@ -0,0 +1,243 @@
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+)
+
+// RPCRequest is a single JSON-RPC call going through a Doer, or a batch of calls.
+type RPCRequest struct {
+	Method string
+	Params interface{}
+	ID     int32
+	// Auth is the session id or API token,
+	// the HTTP transport puts it in the body or the Authorization header depending on Config.Version
+	Auth string
+	// Batch holds the calls of a batch, sent in a single HTTP request with the Auth of the batch.
+	// Method, Params and ID are empty for a batch.
+	Batch []*RPCRequest
+}
+
+// IsBatch reports whether req is a batch of calls.
+func (req *RPCRequest) IsBatch() bool {
+	return req.Batch != nil
+}
+
+// Methods returns the method of req, or the methods of its calls for a batch.
+func (req *RPCRequest) Methods() []string {
+	if !req.IsBatch() {
+		return []string{req.Method}
+	}
+	res := make([]string, len(req.Batch))
+	for i, c := range req.Batch {
+		res[i] = c.Method
+	}
+	return res
+}
+
+// RPCResponse is the answer to an RPCRequest.
+// Exactly one of Result and Error is set when the call reached the API.
+type RPCResponse struct {
+	Result json.RawMessage
+	Error  *Error
+	ID     int32
+	// Endpoint is the url of the frontend that answered, empty if the transport is not HTTP
+	Endpoint string
+	// Batch holds the responses to the calls of a batch, matched to them by ID.
+	// Error is set instead when the whole batch is rejected.
+	Batch []*RPCResponse
+}
+
+// Doer sends JSON-RPC calls.
+// err is something network or marshaling related, API errors are returned in RPCResponse.Error.
+type Doer interface {
+	Do(ctx context.Context, req *RPCRequest) (*RPCResponse, error)
+}
+
+// DoerFunc adapts a function to the Doer interface.
+type DoerFunc func(ctx context.Context, req *RPCRequest) (*RPCResponse, error)
+
+// Do calls f(ctx, req).
+func (f DoerFunc) Do(ctx context.Context, req *RPCRequest) (*RPCResponse, error) {
+	return f(ctx, req)
+}
+
+// Middleware wraps a Doer to observe or modify calls.
+type Middleware func(next Doer) Doer
+
+// Chain wraps d with middlewares, the first one being the outermost.
+func Chain(d Doer, middlewares ...Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		d = middlewares[i](d)
+	}
+	return d
+}
+
+// httpTransport is the default Doer, posting calls to Config.Url or one of Config.Urls.
+type httpTransport struct {
+	api *API
+}
+
+func (t httpTransport) Do(ctx context.Context, req *RPCRequest) (res *RPCResponse, err error) {
+	if req.IsBatch() {
+		return t.doBatch(ctx, req)
+	}
+	auth, bearer := t.api.authPlacement(req.Auth)
+	body, err := json.Marshal(request{"2.0", req.Method, req.Params, auth, req.ID})
+	if err != nil {
+		return
+	}
+	b, url, err := t.api.send(ctx, isIdempotent(req.Method), body, bearer)
+	if err != nil {
+		return
+	}
+	var raw RawResponse
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return
+	}
+	return &RPCResponse{Result: raw.Result, Error: raw.Error, ID: raw.ID, Endpoint: url}, nil
+}
+
+// doBatch posts the calls of a batch as a JSON-RPC array
+func (t httpTransport) doBatch(ctx context.Context, req *RPCRequest) (res *RPCResponse, err error) {
+	auth, bearer := t.api.authPlacement(req.Auth)
+	idempotent := true
+	reqs := make([]request, len(req.Batch))
+	for i, c := range req.Batch {
+		reqs[i] = request{"2.0", c.Method, c.Params, auth, c.ID}
+		idempotent = idempotent && isIdempotent(c.Method)
+	}
+	body, err := json.Marshal(reqs)
+	if err != nil {
+		return
+	}
+	b, url, err := t.api.send(ctx, idempotent, body, bearer)
+	if err != nil {
+		return
+	}
+
+	res = &RPCResponse{Endpoint: url, Batch: []*RPCResponse{}}
+	// a malformed batch is answered with a single error object
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single RawResponse
+		if err = json.Unmarshal(trimmed, &single); err != nil {
+			return nil, err
+		}
+		if single.Error != nil {
+			res.Error = single.Error
+			return
+		}
+	}
+	var raws []RawResponse
+	if err = json.Unmarshal(b, &raws); err != nil {
+		return nil, err
+	}
+	for _, raw := range raws {
+		res.Batch = append(res.Batch, &RPCResponse{Result: raw.Result, Error: raw.Error, ID: raw.ID, Endpoint: url})
+	}
+	return
+}
+
+// doer returns the transport wrapped in Config.Middleware.
+func (api *API) doer() Doer {
+	api.doerOnce.Do(func() {
+		var d Doer = httpTransport{api}
+		if api.Config.Transport != nil {
+			d = api.Config.Transport
+		}
+		api.chain = Chain(d, api.Config.Middleware...)
+	})
+	return api.chain
+}
+
+// Logging logs each call with its duration and outcome, without parameters or results.
+func Logging(l *log.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *RPCRequest) (res *RPCResponse, err error) {
+			start := time.Now()
+			res, err = next.Do(ctx, req)
+			if req.IsBatch() {
+				logBatch(l, req, res, err, time.Since(start))
+				return
+			}
+			switch {
+			case err != nil:
+				l.Printf("%s (id %d) failed after %s: %s", req.Method, req.ID, time.Since(start), err)
+			case res.Error != nil:
+				l.Printf("%s (id %d) returned error %d after %s", req.Method, req.ID, res.Error.Code, time.Since(start))
+			default:
+				l.Printf("%s (id %d) done in %s", req.Method, req.ID, time.Since(start))
+			}
+			return
+		})
+	}
+}
+
+// logBatch is the Logging of a batch
+func logBatch(l *log.Logger, req *RPCRequest, res *RPCResponse, err error, d time.Duration) {
+	methods := strings.Join(req.Methods(), ", ")
+	switch {
+	case err != nil:
+		l.Printf("batch (%s) failed after %s: %s", methods, d, err)
+	case res.Error != nil:
+		l.Printf("batch (%s) returned error %d after %s", methods, res.Error.Code, d)
+	default:
+		l.Printf("batch (%s) done in %s", methods, d)
+	}
+}
+
+// Observe calls before ahead of each call, and the function it returns once the call is done.
+// The context returned by before is passed down the chain, which suits tracing spans and metrics.
+// A batch is observed once, see RPCRequest.Batch.
+func Observe(before func(ctx context.Context, req *RPCRequest) (context.Context, func(*RPCResponse, error))) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *RPCRequest) (res *RPCResponse, err error) {
+			ctx, after := before(ctx, req)
+			res, err = next.Do(ctx, req)
+			if after != nil {
+				after(res, err)
+			}
+			return
+		})
+	}
+}
+
+// InjectAuth sets the auth of each call to the value returned by auth,
+// for example a token fetched from a secret store.
+// user.login and apiinfo.version are left alone since they must be called without auth,
+// batches get the auth of all their calls.
+func InjectAuth(auth func(ctx context.Context) (string, error)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *RPCRequest) (*RPCResponse, error) {
+			switch strings.ToLower(req.Method) {
+			case "user.login", "apiinfo.version":
+				return next.Do(ctx, req)
+			}
+			a, err := auth(ctx)
+			if err != nil {
+				return nil, err
+			}
+			r := *req
+			r.Auth = a
+			return next.Do(ctx, &r)
+		})
+	}
+}
+
+// InjectFault lets fault answer calls instead of the next Doer, to test error handling.
+// Batches are passed to fault as a whole, see RPCRequest.Batch.
+// A call is passed on when fault returns a nil response and a nil error.
+func InjectFault(fault func(ctx context.Context, req *RPCRequest) (*RPCResponse, error)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(ctx context.Context, req *RPCRequest) (*RPCResponse, error) {
+			if res, err := fault(ctx, req); res != nil || err != nil {
+				return res, err
+			}
+			return next.Do(ctx, req)
+		})
+	}
+}
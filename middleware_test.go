@@ -0,0 +1,212 @@
+package zabbix_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+type ctxKey struct{}
+
+func TestMiddlewareOrder(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+
+	var calls []string
+	trace := func(name string) zapi.Middleware {
+		return func(next zapi.Doer) zapi.Doer {
+			return zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+				calls = append(calls, name+" "+req.Method)
+				return next.Do(ctx, req)
+			})
+		}
+	}
+
+	var spans []string
+	observe := zapi.Observe(func(ctx context.Context, req *zapi.RPCRequest) (context.Context, func(*zapi.RPCResponse, error)) {
+		ctx = context.WithValue(ctx, ctxKey{}, req.Method)
+		return ctx, func(res *zapi.RPCResponse, err error) {
+			if err == nil && res.Error != nil {
+				spans = append(spans, req.Method+" error")
+			} else {
+				spans = append(spans, req.Method+" ok")
+			}
+		}
+	})
+	check := func(next zapi.Doer) zapi.Doer {
+		return zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+			if ctx.Value(ctxKey{}) != req.Method {
+				t.Errorf("Observe context not passed down for %s", req.Method)
+			}
+			return next.Do(ctx, req)
+		})
+	}
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Middleware: []zapi.Middleware{trace("outer"), observe, check, trace("inner")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login("Admin", "wrong"); err == nil {
+		t.Fatal("Expected login error")
+	}
+
+	expected := []string{"outer APIInfo.version", "inner APIInfo.version", "outer user.login", "inner user.login"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, calls)
+	}
+	if strings.Join(spans, ",") != "APIInfo.version ok,user.login error" {
+		t.Errorf("Unexpected spans %v", spans)
+	}
+}
+
+func TestInjectAuth(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{Version: "6.4.0"})
+	defer srv.Close()
+
+	// a token obtained out of band
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	session := api.Auth
+
+	api, err = zapi.NewAPI(zapi.Config{
+		Url: srv.URL,
+		Middleware: []zapi.Middleware{zapi.InjectAuth(func(ctx context.Context) (string, error) {
+			return session, nil
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+
+	failing := errors.New("vault sealed")
+	api, err = zapi.NewAPI(zapi.Config{
+		Url: srv.URL,
+		Middleware: []zapi.Middleware{zapi.InjectAuth(func(ctx context.Context) (string, error) {
+			return "", failing
+		})},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != failing {
+		t.Errorf("Expected %v, got %v", failing, err)
+	}
+}
+
+func TestInjectFault(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+
+	fault := zapi.InjectFault(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+		if req.Method == "host.get" {
+			return &zapi.RPCResponse{ID: req.ID, Error: &zapi.Error{Code: -32500, Message: "Application error.", Data: "Injected."}}, nil
+		}
+		return nil, nil
+	})
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Middleware: []zapi.Middleware{fault}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = api.HostsGet(zapi.Params{})
+	var e *zapi.Error
+	if !errors.As(err, &e) || e.Data != "Injected." || e.Method != "host.get" {
+		t.Errorf("Expected injected error, got %v", err)
+	}
+	if _, err = api.HostGroupsGet(zapi.Params{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var methods []string
+	transport := zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+		methods = append(methods, req.Method)
+		var result interface{} = []interface{}{}
+		if req.Method == "APIInfo.version" {
+			result = "5.0.0"
+		}
+		b, _ := json.Marshal(result)
+		return &zapi.RPCResponse{ID: req.ID, Result: b}, nil
+	})
+
+	var buf bytes.Buffer
+	api, err := zapi.NewAPI(zapi.Config{
+		Transport:  transport,
+		Middleware: []zapi.Middleware{zapi.Logging(log.New(&buf, "", 0))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.SetClient(&http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		t.Error("HTTP client used despite custom transport")
+		return nil, errors.New("unexpected")
+	})})
+
+	hosts, err := api.HostsGet(zapi.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 0 || len(methods) != 2 || methods[1] != "host.get" {
+		t.Errorf("Unexpected calls %v", methods)
+	}
+	if !strings.Contains(buf.String(), "host.get (id 2) done in") {
+		t.Errorf("Unexpected log %q", buf.String())
+	}
+}
+
+func TestLoggingBatch(t *testing.T) {
+	transport := zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+		if req.Method == "APIInfo.version" {
+			return &zapi.RPCResponse{ID: req.ID, Result: json.RawMessage(`"5.0.0"`)}, nil
+		}
+		res := &zapi.RPCResponse{}
+		for _, c := range req.Batch {
+			res.Batch = append(res.Batch, &zapi.RPCResponse{ID: c.ID, Result: json.RawMessage(`[]`)})
+		}
+		return res, nil
+	})
+
+	var buf bytes.Buffer
+	api, err := zapi.NewAPI(zapi.Config{
+		Transport:  transport,
+		Middleware: []zapi.Middleware{zapi.Logging(log.New(&buf, "", 0))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := api.NewBatch()
+	b.Add("host.get", zapi.Params{}, nil)
+	b.Add("item.get", zapi.Params{}, nil)
+	if err = b.Do(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "batch (host.get, item.get) done in") || strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("Unexpected log %q", buf.String())
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
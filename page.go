@@ -0,0 +1,226 @@
+package zabbix
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// defaultPageSize is the number of objects per page when PageOptions.Size is zero
+	defaultPageSize = 1000
+	// maxPageWindow is the number of ids a page is looked up in at most
+	maxPageWindow = 100000
+)
+
+// PageOptions controls paginated get calls.
+//
+// The API has no offset nor range filter, so pages are fetched by id: each call asks for Size objects
+// sorted by id among the ids following the last one seen, passed explicitly as a window of consecutive ids
+// that the server turns into a range. The window grows while pages come back short, so sparse ids take
+// more calls. Pagination stops at the highest id matching params after the first page.
+type PageOptions struct {
+	// Size is the number of objects per page, 1000 if zero
+	Size int
+	// After is the id of the last object already handled, pagination resumes after it
+	After string
+}
+
+// idLess compares numeric ids
+func idLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// copyParams returns a shallow copy of params, so paging does not modify the caller's map
+func copyParams(params Params) Params {
+	res := make(Params, len(params)+3)
+	for k, v := range params {
+		res[k] = v
+	}
+	return res
+}
+
+// paramIDs returns the ids given to an ids parameter, false if v is not a list of ids
+func paramIDs(v interface{}) ([]string, bool) {
+	switch ids := v.(type) {
+	case string:
+		return []string{ids}, true
+	case []string:
+		return ids, true
+	case []interface{}:
+		res := make([]string, len(ids))
+		for i, id := range ids {
+			res[i] = fmt.Sprint(id)
+		}
+		return res, true
+	}
+	return nil, false
+}
+
+// idWindow returns the ids from first to last included
+func idWindow(first, last uint64) []string {
+	ids := make([]string, 0, last-first+1)
+	for id := first; id <= last; id++ {
+		ids = append(ids, strconv.FormatUint(id, 10))
+	}
+	return ids
+}
+
+// lastID returns the highest id of the objects matching params, empty if none match
+func (api *API) lastID(ctx context.Context, method, idField string, params Params) (id string, err error) {
+	p := copyParams(params)
+	for k := range p {
+		if strings.HasPrefix(k, "select") || k == "preservekeys" {
+			delete(p, k)
+		}
+	}
+	p["output"] = []string{idField}
+	p["sortfield"] = idField
+	p["sortorder"] = "DESC"
+	p["limit"] = 1
+	var res []map[string]string
+	if err = api.CallWithErrorParseContext(ctx, method, p, &res); err != nil || len(res) == 0 {
+		return
+	}
+	return res[0][idField], nil
+}
+
+// eachPage calls page with params restricted to successive pages of ids, in ascending order.
+// page returns the number of objects it got and the id of the last one.
+// It stops at the first error, including cancellation of ctx.
+func (api *API) eachPage(ctx context.Context, method, idField, idsParam string, params Params, opts PageOptions, page func(Params) (int, string, error)) error {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	// a limit of the caller bounds the number of objects
+	remaining := -1
+	if l, ok := params["limit"]; ok {
+		n, err := strconv.Atoi(fmt.Sprint(l))
+		if err != nil {
+			return fmt.Errorf("%s: bad limit %v", method, l)
+		}
+		remaining = n
+	}
+	params = copyParams(params)
+	delete(params, "limit")
+	params["sortfield"] = idField
+	params["sortorder"] = "ASC"
+	get := func(ids []string) (int, string, error) {
+		p := copyParams(params)
+		if ids != nil {
+			p[idsParam] = ids
+		}
+		p["limit"] = size
+		if remaining >= 0 && remaining < size {
+			p["limit"] = remaining
+		}
+		n, last, err := page(p)
+		remaining -= n
+		return n, last, err
+	}
+
+	// ids given by the caller are paged as they are
+	if v, ok := params[idsParam]; ok {
+		ids, ok := paramIDs(v)
+		if !ok {
+			return fmt.Errorf("%s: %s must be an id or a list of ids to page, got %T", method, idsParam, v)
+		}
+		ids = append([]string(nil), ids...)
+		sort.Slice(ids, func(i, j int) bool { return idLess(ids[i], ids[j]) })
+		for len(ids) > 0 && opts.After != "" && !idLess(opts.After, ids[0]) {
+			ids = ids[1:]
+		}
+		for start := 0; start < len(ids) && remaining != 0; start += size {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := start + size
+			if end > len(ids) {
+				end = len(ids)
+			}
+			if _, _, err := get(ids[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	after := opts.After
+	if after == "" {
+		// the first page needs no window
+		n, last, err := get(nil)
+		if err != nil || n < size || remaining == 0 {
+			return err
+		}
+		after = last
+	}
+	cursor, err := strconv.ParseUint(after, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: bad id %q", method, after)
+	}
+	last, err := api.lastID(ctx, method, idField, params)
+	if err != nil || last == "" {
+		return err
+	}
+	max, err := strconv.ParseUint(last, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s: bad id %q", method, last)
+	}
+
+	window := uint64(size)
+	for cursor < max && remaining != 0 {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		end := max
+		if max-cursor > window {
+			end = cursor + window
+		}
+		n, last, err := get(idWindow(cursor+1, end))
+		if err != nil {
+			return err
+		}
+		if n < size || last == "" {
+			// the window is exhausted, widen the next one
+			cursor = end
+			if window < maxPageWindow {
+				window *= 2
+			}
+			continue
+		}
+		if cursor, err = strconv.ParseUint(last, 10, 64); err != nil {
+			return fmt.Errorf("%s: bad id %q", method, last)
+		}
+	}
+	return nil
+}
+
+// streamPages sends the objects of each page to a channel.
+// The error channel receives the error that stopped the stream, if any, and is closed after the objects channel.
+func streamPages[T any](ctx context.Context, pages func(func([]T) error) error) (<-chan T, <-chan error) {
+	out, errc := make(chan T), make(chan error, 1)
+	go func() {
+		defer close(errc)
+		defer close(out)
+		err := pages(func(page []T) error {
+			for _, o := range page {
+				select {
+				case out <- o:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errc <- err
+		}
+	}()
+	return out, errc
+}
@@ -0,0 +1,233 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+func pagedAPI(t *testing.T, hosts int) (*zapi.API, zapi.Hosts) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	t.Cleanup(srv.Close)
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	groups := zapi.HostGroups{{Name: "paged"}}
+	if err = api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	created := make(zapi.Hosts, hosts)
+	for i := range created {
+		created[i] = zapi.Host{Host: fmt.Sprintf("host-%02d", i), GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}}}
+	}
+	if err = api.HostsCreate(created); err != nil {
+		t.Fatal(err)
+	}
+	return api, created
+}
+
+func TestHostsGetPages(t *testing.T) {
+	api, created := pagedAPI(t, 25)
+	ctx := context.Background()
+
+	var sizes []int
+	var got []string
+	params := zapi.Params{"selectGroups": "extend"}
+	err := api.HostsGetPages(ctx, params, zapi.PageOptions{Size: 10}, func(hosts zapi.Hosts) error {
+		sizes = append(sizes, len(hosts))
+		for _, h := range hosts {
+			if len(h.GroupIds) != 1 {
+				t.Errorf("Expected groups of %s", h.Host)
+			}
+			got = append(got, h.HostID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(sizes) != "[10 10 5]" {
+		t.Errorf("Unexpected page sizes %v", sizes)
+	}
+	for i, h := range created {
+		if got[i] != h.HostID {
+			t.Fatalf("Expected host %s at %d, got %s", h.HostID, i, got[i])
+		}
+	}
+	if len(params) != 1 {
+		t.Errorf("Params modified: %v", params)
+	}
+
+	// resume after a cursor
+	got = nil
+	err = api.HostsGetPages(ctx, zapi.Params{}, zapi.PageOptions{Size: 10, After: created[19].HostID}, func(hosts zapi.Hosts) error {
+		for _, h := range hosts {
+			got = append(got, h.Host)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != "[host-20 host-21 host-22 host-23 host-24]" {
+		t.Errorf("Unexpected hosts %v", got)
+	}
+
+	// a limit of the caller bounds the number of hosts
+	got = nil
+	err = api.HostsGetPages(ctx, zapi.Params{"limit": 12}, zapi.PageOptions{Size: 10}, func(hosts zapi.Hosts) error {
+		for _, h := range hosts {
+			got = append(got, h.HostID)
+		}
+		return nil
+	})
+	if err != nil || len(got) != 12 || got[11] != created[11].HostID {
+		t.Errorf("Expected the 12 first hosts, got %v, %v", got, err)
+	}
+
+	// ids given by the caller
+	got = nil
+	ids := []string{created[3].HostID, created[1].HostID, created[2].HostID}
+	err = api.HostsGetPages(ctx, zapi.Params{"hostids": ids}, zapi.PageOptions{Size: 2, After: created[1].HostID}, func(hosts zapi.Hosts) error {
+		for _, h := range hosts {
+			got = append(got, h.Host)
+		}
+		return nil
+	})
+	if err != nil || fmt.Sprint(got) != "[host-02 host-03]" {
+		t.Errorf("Unexpected hosts %v, %v", got, err)
+	}
+
+	// stop from the callback
+	stop := errors.New("stop")
+	pages := 0
+	err = api.HostsGetPages(ctx, zapi.Params{}, zapi.PageOptions{Size: 10}, func(hosts zapi.Hosts) error {
+		pages++
+		return stop
+	})
+	if err != stop || pages != 1 {
+		t.Errorf("Expected to stop after 1 page, got %d pages and %v", pages, err)
+	}
+}
+
+func TestHostsGetPagesBounded(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	t.Cleanup(srv.Close)
+	// the number of hosts returned by each host.get
+	var returned []int
+	count := zapi.Observe(func(ctx context.Context, req *zapi.RPCRequest) (context.Context, func(*zapi.RPCResponse, error)) {
+		if req.Method != "host.get" {
+			return ctx, nil
+		}
+		return ctx, func(res *zapi.RPCResponse, err error) {
+			var hosts []json.RawMessage
+			if err == nil && json.Unmarshal(res.Result, &hosts) == nil {
+				returned = append(returned, len(hosts))
+			}
+		}
+	})
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Middleware: []zapi.Middleware{count}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	groups := zapi.HostGroups{{Name: "paged"}}
+	if err = api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	created := make(zapi.Hosts, 40)
+	for i := range created {
+		created[i] = zapi.Host{Host: fmt.Sprintf("host-%02d", i), GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}}}
+	}
+	if err = api.HostsCreate(created); err != nil {
+		t.Fatal(err)
+	}
+	// leave a gap in the ids
+	if err = api.HostsDelete(created[5:35]); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	collect := func(hosts zapi.Hosts) error {
+		for _, h := range hosts {
+			got = append(got, h.Host)
+		}
+		return nil
+	}
+	returned = nil
+	if err = api.HostsGetPages(context.Background(), zapi.Params{}, zapi.PageOptions{Size: 2}, collect); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != "[host-00 host-01 host-02 host-03 host-04 host-35 host-36 host-37 host-38 host-39]" {
+		t.Errorf("Unexpected hosts %v", got)
+	}
+	for _, n := range returned {
+		if n > 2 {
+			t.Errorf("Expected at most a page per call, got %v", returned)
+			break
+		}
+	}
+
+	// resuming does not go through the hosts before the cursor
+	got, returned = nil, nil
+	if err = api.HostsGetPages(context.Background(), zapi.Params{}, zapi.PageOptions{Size: 2, After: created[36].HostID}, collect); err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, n := range returned {
+		total += n
+	}
+	if fmt.Sprint(got) != "[host-37 host-38 host-39]" || total != 4 {
+		t.Errorf("Expected the 3 last hosts and the highest id, got %v in %v", got, returned)
+	}
+}
+
+func TestItemsGetStream(t *testing.T) {
+	api, hosts := pagedAPI(t, 2)
+
+	items := make(zapi.Items, 30)
+	for i := range items {
+		items[i] = zapi.Item{HostID: hosts[i%2].HostID, Key: fmt.Sprintf("key%d", i), Name: "item", Type: zapi.ZabbixTrapper}
+	}
+	if err := api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+
+	out, errc := api.ItemsGetStream(context.Background(), zapi.Params{"hostids": hosts[0].HostID}, zapi.PageOptions{Size: 4})
+	n := 0
+	for item := range out {
+		if item.HostID != hosts[0].HostID {
+			t.Errorf("Unexpected item %#v", item)
+		}
+		n++
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n != 15 {
+		t.Errorf("Expected 15 items, got %d", n)
+	}
+
+	// cancel while streaming
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errc = api.ItemsGetStream(ctx, zapi.Params{}, zapi.PageOptions{Size: 4})
+	<-out
+	cancel()
+	for range out {
+	}
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected canceled, got %v", err)
+	}
+}
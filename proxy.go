@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 // Proxy represent Zabbix proxy object
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/proxy/object
 type Proxy struct {
@@ -11,12 +13,19 @@ type Proxy struct {
 // Proxies is an array of Proxy
 type Proxies []Proxy
 
+// ProxyResource describes proxy objects
+var ProxyResource = &Resource[Proxy]{
+	Object:  "proxy",
+	IDField: "proxyid",
+}
+
 // ProxiesGet Wrapper for proxy.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/proxy/get
 func (api *API) ProxiesGet(params Params) (res Proxies, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("proxy.get", params, &res)
-	return
+	return api.ProxiesGetContext(context.Background(), params)
+}
+
+// ProxiesGetContext is like ProxiesGet but uses ctx for the request.
+func (api *API) ProxiesGetContext(ctx context.Context, params Params) (res Proxies, err error) {
+	return ProxyResource.Get(ctx, api, params)
 }
@@ -0,0 +1,405 @@
+package zabbix
+
+import "context"
+
+// Query renders typed get options to the Params sent to the API.
+type Query interface {
+	Params() Params
+}
+
+// SortOrder of get results
+type SortOrder string
+
+const (
+	// SortAsc sorts in ascending order, the default
+	SortAsc SortOrder = "ASC"
+	// SortDesc sorts in descending order
+	SortDesc SortOrder = "DESC"
+)
+
+// CommonQuery holds the options accepted by every get method.
+// https://www.zabbix.com/documentation/5.0/manual/api/reference_commentary#common-get-method-parameters
+type CommonQuery struct {
+	// Output lists the returned properties, all of them if empty
+	Output []string
+	// Filter returns only objects whose properties exactly match one of the values
+	Filter map[string][]string
+	// Search returns objects whose properties contain the values
+	Search map[string]string
+	// SearchWildcards enables "*" in Search values
+	SearchWildcards bool
+	// StartSearch matches Search values at the beginning of properties only
+	StartSearch bool
+	// SearchByAny returns objects matching any of Filter or Search instead of all of them
+	SearchByAny bool
+	// Limit caps the number of returned objects, unlimited if zero
+	Limit int
+	// SortField lists the properties to sort by
+	SortField []string
+	SortOrder SortOrder
+	// Extra holds parameters without a typed option, they override typed ones
+	Extra Params
+}
+
+// Params implements Query.
+func (q CommonQuery) Params() Params {
+	p := Params{"output": "extend"}
+	if len(q.Output) > 0 {
+		p["output"] = q.Output
+	}
+	if len(q.Filter) > 0 {
+		p["filter"] = q.Filter
+	}
+	if len(q.Search) > 0 {
+		p["search"] = q.Search
+	}
+	setFlag(p, "searchWildcardsEnabled", q.SearchWildcards)
+	setFlag(p, "startSearch", q.StartSearch)
+	setFlag(p, "searchByAny", q.SearchByAny)
+	if q.Limit > 0 {
+		p["limit"] = q.Limit
+	}
+	if len(q.SortField) > 0 {
+		p["sortfield"] = q.SortField
+	}
+	if q.SortOrder != "" {
+		p["sortorder"] = q.SortOrder
+	}
+	return p
+}
+
+// with applies the typed options of a resource query, then Extra
+func (q CommonQuery) with(options func(Params)) Params {
+	p := q.Params()
+	options(p)
+	for k, v := range q.Extra {
+		p[k] = v
+	}
+	return p
+}
+
+// setIDs sets an id filter unless ids is empty
+func setIDs(p Params, key string, ids []string) {
+	if len(ids) > 0 {
+		p[key] = ids
+	}
+}
+
+// setFlag sets a flag parameter, the API only checks their presence
+func setFlag(p Params, key string, set bool) {
+	if set {
+		p[key] = true
+	}
+}
+
+// setBool sets a boolean parameter unless b is nil
+func setBool(p Params, key string, b *bool) {
+	if b != nil {
+		p[key] = *b
+	}
+}
+
+// setTime sets a Unix time unless t is zero
+func setTime(p Params, key string, t int64) {
+	if t != 0 {
+		p[key] = t
+	}
+}
+
+// setSelect requests related objects with all their properties
+func setSelect(p Params, key string, set bool) {
+	if set {
+		p[key] = "extend"
+	}
+}
+
+// HostQuery holds the options of host.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/host/get
+type HostQuery struct {
+	CommonQuery
+	HostIDs     []string
+	GroupIDs    []string
+	TemplateIDs []string
+	ProxyIDs    []string
+	// MonitoredHosts returns only monitored hosts
+	MonitoredHosts bool
+	// WithItems returns only hosts having items
+	WithItems bool
+	// WithTriggers returns only hosts having triggers
+	WithTriggers bool
+
+	SelectGroups          bool
+	SelectInterfaces      bool
+	SelectInventory       bool
+	SelectMacros          bool
+	SelectParentTemplates bool
+	SelectTags            bool
+}
+
+// Params implements Query.
+func (q HostQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "templateids", q.TemplateIDs)
+		setIDs(p, "proxyids", q.ProxyIDs)
+		setFlag(p, "monitored_hosts", q.MonitoredHosts)
+		setFlag(p, "with_items", q.WithItems)
+		setFlag(p, "with_triggers", q.WithTriggers)
+		setSelect(p, "selectGroups", q.SelectGroups)
+		setSelect(p, "selectInterfaces", q.SelectInterfaces)
+		setSelect(p, "selectInventory", q.SelectInventory)
+		setSelect(p, "selectMacros", q.SelectMacros)
+		setSelect(p, "selectParentTemplates", q.SelectParentTemplates)
+		setSelect(p, "selectTags", q.SelectTags)
+	})
+}
+
+// HostGroupQuery holds the options of hostgroup.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/hostgroup/get
+type HostGroupQuery struct {
+	CommonQuery
+	GroupIDs    []string
+	HostIDs     []string
+	TemplateIDs []string
+	// RealHosts returns only groups containing hosts
+	RealHosts bool
+
+	SelectHosts     bool
+	SelectTemplates bool
+}
+
+// Params implements Query.
+func (q HostGroupQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "templateids", q.TemplateIDs)
+		setFlag(p, "real_hosts", q.RealHosts)
+		setSelect(p, "selectHosts", q.SelectHosts)
+		setSelect(p, "selectTemplates", q.SelectTemplates)
+	})
+}
+
+// TemplateQuery holds the options of template.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/template/get
+type TemplateQuery struct {
+	CommonQuery
+	TemplateIDs []string
+	GroupIDs    []string
+	HostIDs     []string
+
+	SelectGroups          bool
+	SelectHosts           bool
+	SelectMacros          bool
+	SelectParentTemplates bool
+	SelectTags            bool
+}
+
+// Params implements Query.
+func (q TemplateQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "templateids", q.TemplateIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setSelect(p, "selectGroups", q.SelectGroups)
+		setSelect(p, "selectHosts", q.SelectHosts)
+		setSelect(p, "selectMacros", q.SelectMacros)
+		setSelect(p, "selectParentTemplates", q.SelectParentTemplates)
+		setSelect(p, "selectTags", q.SelectTags)
+	})
+}
+
+// ItemQuery holds the options of item.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/item/get
+type ItemQuery struct {
+	CommonQuery
+	ItemIDs        []string
+	HostIDs        []string
+	GroupIDs       []string
+	TemplateIDs    []string
+	ApplicationIDs []string
+	// Monitored returns only enabled items of monitored hosts
+	Monitored bool
+	// WebItems also returns web scenario items
+	WebItems bool
+
+	SelectHosts         bool
+	SelectPreprocessing bool
+	SelectTags          bool
+	SelectTriggers      bool
+}
+
+// Params implements Query.
+func (q ItemQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "itemids", q.ItemIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "templateids", q.TemplateIDs)
+		setIDs(p, "applicationids", q.ApplicationIDs)
+		setFlag(p, "monitored", q.Monitored)
+		setFlag(p, "webitems", q.WebItems)
+		setSelect(p, "selectHosts", q.SelectHosts)
+		setSelect(p, "selectPreprocessing", q.SelectPreprocessing)
+		setSelect(p, "selectTags", q.SelectTags)
+		setSelect(p, "selectTriggers", q.SelectTriggers)
+	})
+}
+
+// TriggerQuery holds the options of trigger.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/trigger/get
+type TriggerQuery struct {
+	CommonQuery
+	TriggerIDs []string
+	HostIDs    []string
+	GroupIDs   []string
+	ItemIDs    []string
+	// Monitored returns only enabled triggers of monitored hosts and items
+	Monitored bool
+	// OnlyTrue returns only triggers recently in problem state
+	OnlyTrue bool
+	// MinSeverity returns only triggers of at least this severity, ignored if zero
+	MinSeverity SeverityType
+	// ExpandDescription expands macros in trigger names
+	ExpandDescription bool
+	// ExpandExpression expands functions and macros in expressions
+	ExpandExpression bool
+
+	SelectDependencies bool
+	SelectFunctions    bool
+	SelectHosts        bool
+	SelectItems        bool
+	SelectTags         bool
+}
+
+// Params implements Query.
+func (q TriggerQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "triggerids", q.TriggerIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "itemids", q.ItemIDs)
+		setFlag(p, "monitored", q.Monitored)
+		setFlag(p, "only_true", q.OnlyTrue)
+		if q.MinSeverity != 0 {
+			p["min_severity"] = q.MinSeverity
+		}
+		setFlag(p, "expandDescription", q.ExpandDescription)
+		setFlag(p, "expandExpression", q.ExpandExpression)
+		setSelect(p, "selectDependencies", q.SelectDependencies)
+		setSelect(p, "selectFunctions", q.SelectFunctions)
+		setSelect(p, "selectHosts", q.SelectHosts)
+		setSelect(p, "selectItems", q.SelectItems)
+		setSelect(p, "selectTags", q.SelectTags)
+	})
+}
+
+// EventQuery holds the options of event.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/event/get
+type EventQuery struct {
+	CommonQuery
+	EventIDs  []string
+	GroupIDs  []string
+	HostIDs   []string
+	ObjectIDs []string
+	// Source and Object select the type of events, trigger events if both are zero
+	Source EventSource
+	Object EventObject
+	// Acknowledged returns only acknowledged or unacknowledged events if not nil
+	Acknowledged *bool
+	// Suppressed returns only suppressed or unsuppressed events if not nil
+	Suppressed *bool
+	// Severities returns only events of these severities
+	Severities []SeverityType
+	// TimeFrom and TimeTill are Unix times bounding the creation of events, ignored if zero
+	TimeFrom int64
+	TimeTill int64
+	// Value returns only events of these values, such as Problem
+	Value []ValueType
+
+	SelectHosts        bool
+	SelectTags         bool
+	SelectAcknowledges bool
+}
+
+// Params implements Query.
+func (q EventQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "eventids", q.EventIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "objectids", q.ObjectIDs)
+		p["source"] = q.Source
+		p["object"] = q.Object
+		setBool(p, "acknowledged", q.Acknowledged)
+		setBool(p, "suppressed", q.Suppressed)
+		if len(q.Severities) > 0 {
+			p["severities"] = q.Severities
+		}
+		setTime(p, "time_from", q.TimeFrom)
+		setTime(p, "time_till", q.TimeTill)
+		if len(q.Value) > 0 {
+			p["value"] = q.Value
+		}
+		setSelect(p, "selectHosts", q.SelectHosts)
+		setSelect(p, "selectTags", q.SelectTags)
+		// unlike problem.get
+		setSelect(p, "select_acknowledges", q.SelectAcknowledges)
+	})
+}
+
+// ProblemQuery holds the options of problem.get
+// https://www.zabbix.com/documentation/5.0/manual/api/reference/problem/get
+type ProblemQuery struct {
+	CommonQuery
+	EventIDs  []string
+	GroupIDs  []string
+	HostIDs   []string
+	ObjectIDs []string
+	// Source and Object select the type of problems, trigger problems if both are zero
+	Source EventSource
+	Object EventObject
+	// Acknowledged returns only acknowledged or unacknowledged problems if not nil
+	Acknowledged *bool
+	// Suppressed returns only suppressed or unsuppressed problems if not nil
+	Suppressed *bool
+	// Severities returns only problems of these severities
+	Severities []SeverityType
+	// Recent also returns problems resolved recently
+	Recent bool
+	// TimeFrom and TimeTill are Unix times bounding the creation of problems, ignored if zero
+	TimeFrom int64
+	TimeTill int64
+
+	SelectTags         bool
+	SelectAcknowledges bool
+}
+
+// Params implements Query.
+func (q ProblemQuery) Params() Params {
+	return q.with(func(p Params) {
+		setIDs(p, "eventids", q.EventIDs)
+		setIDs(p, "groupids", q.GroupIDs)
+		setIDs(p, "hostids", q.HostIDs)
+		setIDs(p, "objectids", q.ObjectIDs)
+		p["source"] = q.Source
+		p["object"] = q.Object
+		setBool(p, "acknowledged", q.Acknowledged)
+		setBool(p, "suppressed", q.Suppressed)
+		if len(q.Severities) > 0 {
+			p["severities"] = q.Severities
+		}
+		// the API only checks the presence of recent
+		setFlag(p, "recent", q.Recent)
+		setTime(p, "time_from", q.TimeFrom)
+		setTime(p, "time_till", q.TimeTill)
+		setSelect(p, "selectTags", q.SelectTags)
+		setSelect(p, "selectAcknowledges", q.SelectAcknowledges)
+	})
+}
+
+// Query Gets objects matching q, see Get.
+func (r *Resource[T]) Query(ctx context.Context, api *API, q Query) ([]T, error) {
+	return r.Get(ctx, api, q.Params())
+}
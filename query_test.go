@@ -0,0 +1,68 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestQueryParams(t *testing.T) {
+	for expected, q := range map[string]zapi.Query{
+		`{"output":"extend"}`: zapi.HostQuery{},
+		`{"filter":{"host":["a","b"]},"groupids":["1"],"limit":10,"output":["hostid","host"],"search":{"name":"web*"},"searchWildcardsEnabled":true,"selectInterfaces":"extend","selectTags":"extend","sortfield":["name"],"sortorder":"DESC","templateids":["2","3"]}`: zapi.HostQuery{
+			CommonQuery: zapi.CommonQuery{
+				Output:          []string{"hostid", "host"},
+				Filter:          map[string][]string{"host": {"a", "b"}},
+				Search:          map[string]string{"name": "web*"},
+				SearchWildcards: true,
+				Limit:           10,
+				SortField:       []string{"name"},
+				SortOrder:       zapi.SortDesc,
+			},
+			GroupIDs:         []string{"1"},
+			TemplateIDs:      []string{"2", "3"},
+			SelectInterfaces: true,
+			SelectTags:       true,
+		},
+		`{"hostids":["1"],"min_severity":4,"only_true":true,"output":"extend","selectHosts":["name"]}`: zapi.TriggerQuery{
+			CommonQuery: zapi.CommonQuery{Extra: zapi.Params{"selectHosts": []string{"name"}}},
+			HostIDs:     []string{"1"},
+			OnlyTrue:    true,
+			MinSeverity: zapi.High,
+			SelectHosts: true,
+		},
+		`{"hostids":["1"],"monitored":true,"output":"extend","selectPreprocessing":"extend"}`: zapi.ItemQuery{
+			HostIDs: []string{"1"}, Monitored: true, SelectPreprocessing: true,
+		},
+	} {
+		b, err := json.Marshal(q.Params())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expected {
+			t.Errorf("Expected %s, got %s", expected, b)
+		}
+	}
+}
+
+func TestHostsQuery(t *testing.T) {
+	api, created := pagedAPI(t, 5)
+
+	hosts, err := api.HostsQuery(zapi.HostQuery{
+		CommonQuery: zapi.CommonQuery{
+			Filter:    map[string][]string{"host": {"host-01", "host-03", "host-04"}},
+			Limit:     2,
+			SortField: []string{"host"},
+			SortOrder: zapi.SortDesc,
+		},
+		GroupIDs:     []string{created[0].GroupIds[0].GroupID},
+		SelectGroups: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].Host != "host-04" || hosts[1].Host != "host-03" || len(hosts[0].GroupIds) != 1 {
+		t.Errorf("Unexpected hosts %#v", hosts)
+	}
+}
@@ -0,0 +1,80 @@
+package zabbix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// redacted replaces secret values in logs and recorded fixtures
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the object keys whose values are never written out
+var sensitiveKeys = map[string]bool{
+	"auth":             true,
+	"password":         true,
+	"passwd":           true,
+	"token":            true,
+	"sessionid":        true,
+	"tls_psk":          true,
+	"tls_psk_identity": true,
+	// SNMP interface details
+	"authpassphrase": true,
+	"privpassphrase": true,
+	"community":      true,
+	// SNMP items and discovery rules
+	"snmp_community":        true,
+	"snmpv3_authpassphrase": true,
+	"snmpv3_privpassphrase": true,
+	// SSH agent items
+	"privatekey": true,
+	// HTTP agent items
+	"headers":          true,
+	"http_password":    true,
+	"ssl_key_password": true,
+}
+
+// secretMacroType is the usermacro type whose value is hidden by the frontend,
+// values of macros without a type are redacted as well
+const secretMacroType = "1"
+
+// redactJSON returns b with the values of sensitive keys replaced, at any depth.
+// Invalid JSON is returned unchanged.
+func redactJSON(b []byte) []byte {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return b
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return b
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// the type of macros is unknown when it is not sent, such as with Macro
+		if _, ok := v["macro"]; ok && (v["type"] == nil || fmt.Sprint(v["type"]) == secretMacroType) {
+			if _, ok := v["value"]; ok {
+				v["value"] = redacted
+			}
+		}
+		for k, e := range v {
+			if sensitiveKeys[strings.ToLower(k)] {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = redactValue(e)
+		}
+	}
+	return v
+}
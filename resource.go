@@ -0,0 +1,223 @@
+package zabbix
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Resource describes an API object type once: the prefix of its methods,
+// its id field and the Go type it is decoded into.
+// All methods behave the same for every resource:
+//
+//	Get          calls <Object>.get, output defaults to "extend"
+//	GetOne       gets an object by id, ExpectedOneResult unless exactly one matches
+//	Create       calls <Object>.create and sets the ids of the objects
+//	Update       calls <Object>.update
+//	Delete       calls <Object>.delete and clears the ids of the objects
+//	DeleteByIDs  calls <Object>.delete with ids
+//	Exists       reports whether an object with the given id exists
+//	GetPages     gets objects page by page, see PageOptions
+//	GetStream    gets objects page by page and delivers them on a channel
+//
+// Resources of this package are declared as variables such as HostResource,
+// new ones can be declared the same way:
+//
+//	var MaintenanceResource = &zabbix.Resource[Maintenance]{Object: "maintenance", IDField: "maintenanceid"}
+//	maintenances, err := MaintenanceResource.Get(ctx, api, zabbix.Params{})
+type Resource[T any] struct {
+	// Object is the prefix of the methods, such as "host" for host.get
+	Object string
+	// IDField is the id attribute, such as "hostid". T must have a string field tagged with it.
+	IDField string
+	// IDsKey is the get parameter filtering ids and the key of ids in
+	// create, update and delete results, IDField+"s" if empty
+	IDsKey string
+	// DeleteKey is the key of ids in delete results when it differs from IDsKey
+	DeleteKey string
+	// Prepare is called on objects before they are sent by Create and Update, may be nil
+	Prepare func([]T)
+	// Decode fixes up each object returned by Get, may be nil.
+	// Objects failing to decode are skipped in lenient mode.
+	Decode func(*T) *DecodeError
+
+	once    sync.Once
+	idIndex []int
+}
+
+func (r *Resource[T]) idsKey() string {
+	if r.IDsKey != "" {
+		return r.IDsKey
+	}
+	return r.IDField + "s"
+}
+
+// idField returns the field of o holding the id, it panics if T has none.
+func (r *Resource[T]) idField(o *T) reflect.Value {
+	r.once.Do(func() {
+		t := reflect.TypeOf(o).Elem()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == r.IDField && f.Type.Kind() == reflect.String {
+				r.idIndex = f.Index
+				return
+			}
+		}
+		panic(fmt.Sprintf("zabbix: %s has no string field tagged %q", t, r.IDField))
+	})
+	return reflect.ValueOf(o).Elem().FieldByIndex(r.idIndex)
+}
+
+// ID returns the id of o.
+func (r *Resource[T]) ID(o *T) string {
+	return r.idField(o).String()
+}
+
+// SetID sets the id of o.
+func (r *Resource[T]) SetID(o *T, id string) {
+	r.idField(o).SetString(id)
+}
+
+// Get Wrapper for <Object>.get
+func (r *Resource[T]) Get(ctx context.Context, api *API, params Params) (res []T, err error) {
+	p := copyParams(params)
+	if _, present := p["output"]; !present {
+		p["output"] = "extend"
+	}
+	if err = api.CallWithErrorParseContext(ctx, r.Object+".get", p, &res); err != nil || r.Decode == nil {
+		return
+	}
+
+	decoded := res[:0]
+	for i := range res {
+		if e := r.Decode(&res[i]); e != nil {
+			if err = api.decodeFailed(e); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		decoded = append(decoded, res[i])
+	}
+	return decoded, nil
+}
+
+// GetOne Gets an object by id only if there is exactly 1 matching object.
+func (r *Resource[T]) GetOne(ctx context.Context, api *API, id string) (res *T, err error) {
+	objects, err := r.Get(ctx, api, Params{r.idsKey(): id})
+	if err != nil {
+		return
+	}
+
+	if len(objects) == 1 {
+		res = &objects[0]
+	} else {
+		e := ExpectedOneResult(len(objects))
+		err = &e
+	}
+	return
+}
+
+// Exists Reports whether an object with this id exists and is readable.
+func (r *Resource[T]) Exists(ctx context.Context, api *API, id string) (bool, error) {
+	var objects []map[string]interface{}
+	err := api.CallWithErrorParseContext(ctx, r.Object+".get", Params{r.idsKey(): id, "output": []string{r.IDField}}, &objects)
+	return len(objects) > 0, err
+}
+
+// GetPages Gets objects page by page, see PageOptions.
+// fn is called for each page, an error returned by fn stops the pagination and is returned.
+func (r *Resource[T]) GetPages(ctx context.Context, api *API, params Params, opts PageOptions, fn func([]T) error) error {
+	return api.eachPage(ctx, r.Object+".get", r.IDField, r.idsKey(), params, opts, func(p Params) (int, string, error) {
+		objects, err := r.Get(ctx, api, p)
+		if err != nil || len(objects) == 0 {
+			return 0, "", err
+		}
+		return len(objects), r.ID(&objects[len(objects)-1]), fn(objects)
+	})
+}
+
+// GetStream is like GetPages but delivers objects one by one on a channel.
+// The error channel is closed once all objects are delivered, after receiving the error that stopped the stream if any.
+func (r *Resource[T]) GetStream(ctx context.Context, api *API, params Params, opts PageOptions) (<-chan T, <-chan error) {
+	return streamPages(ctx, func(page func([]T) error) error {
+		return r.GetPages(ctx, api, params, opts, page)
+	})
+}
+
+// Create Wrapper for <Object>.create, sets the ids of objects.
+func (r *Resource[T]) Create(ctx context.Context, api *API, objects []T) (err error) {
+	if r.Prepare != nil {
+		r.Prepare(objects)
+	}
+	// payloads are adapted to the version, detect it before the first call
+	if err = api.detectVersion(ctx); err != nil {
+		return
+	}
+	payload, err := api.adaptPayload(r.Object, objects)
+	if err != nil {
+		return
+	}
+	ids, err := api.callIDs(ctx, r.Object+".create", payload, r.idsKey())
+	if err != nil {
+		return
+	}
+	if len(ids) != len(objects) {
+		return &ExpectedMore{len(objects), len(ids)}
+	}
+	for i, id := range ids {
+		r.SetID(&objects[i], id)
+	}
+	return
+}
+
+// Update Wrapper for <Object>.update
+func (r *Resource[T]) Update(ctx context.Context, api *API, objects []T) (err error) {
+	if r.Prepare != nil {
+		r.Prepare(objects)
+	}
+	// payloads are adapted to the version, detect it before the first call
+	if err = api.detectVersion(ctx); err != nil {
+		return
+	}
+	payload, err := api.adaptPayload(r.Object, objects)
+	if err != nil {
+		return
+	}
+	ids, err := api.callIDs(ctx, r.Object+".update", payload, r.idsKey())
+	if err == nil && len(ids) != len(objects) {
+		err = &ExpectedMore{len(objects), len(ids)}
+	}
+	return
+}
+
+// Delete Wrapper for <Object>.delete
+// Cleans the ids of objects if call succeed.
+func (r *Resource[T]) Delete(ctx context.Context, api *API, objects []T) (err error) {
+	ids := make([]string, len(objects))
+	for i := range objects {
+		ids[i] = r.ID(&objects[i])
+	}
+
+	if err = r.DeleteByIDs(ctx, api, ids); err == nil {
+		for i := range objects {
+			r.SetID(&objects[i], "")
+		}
+	}
+	return
+}
+
+// DeleteByIDs Wrapper for <Object>.delete
+func (r *Resource[T]) DeleteByIDs(ctx context.Context, api *API, ids []string) (err error) {
+	key := r.DeleteKey
+	if key == "" {
+		key = r.idsKey()
+	}
+	deleted, err := api.callIDs(ctx, r.Object+".delete", ids, key)
+	if err == nil && len(deleted) != len(ids) {
+		err = &ExpectedMore{len(ids), len(deleted)}
+	}
+	return
+}
@@ -0,0 +1,131 @@
+package zabbix_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+// proxyName is a resource type declared outside the package
+type proxyName struct {
+	ID   string `json:"proxyid,omitempty"`
+	Name string `json:"host"`
+}
+
+var proxyNames = &zapi.Resource[proxyName]{Object: "proxy", IDField: "proxyid"}
+
+func TestResource(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	proxies := []proxyName{{Name: "proxy1"}, {Name: "proxy2"}}
+	if err = proxyNames.Create(ctx, api, proxies); err != nil {
+		t.Fatal(err)
+	}
+	if proxies[0].ID == "" || proxies[1].ID == "" {
+		t.Fatalf("Ids not set: %#v", proxies)
+	}
+
+	proxies[1].Name = "renamed"
+	if err = proxyNames.Update(ctx, api, proxies[1:]); err != nil {
+		t.Fatal(err)
+	}
+	p, err := proxyNames.GetOne(ctx, api, proxies[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "renamed" {
+		t.Errorf("Expected renamed proxy, got %#v", p)
+	}
+
+	// the typed wrappers share the same resource behaviour
+	typed, err := api.ProxiesGet(zapi.Params{"proxyids": proxies[0].ID})
+	if err != nil || len(typed) != 1 || typed[0].Host != "proxy1" {
+		t.Errorf("Unexpected proxies %#v, %v", typed, err)
+	}
+
+	id := proxies[0].ID
+	if err = proxyNames.Delete(ctx, api, proxies[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if proxies[0].ID != "" {
+		t.Errorf("Id not cleared: %#v", proxies[0])
+	}
+	for id, expected := range map[string]bool{id: false, proxies[1].ID: true} {
+		exists, err := proxyNames.Exists(ctx, api, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists != expected {
+			t.Errorf("Expected exists %v for %s", expected, id)
+		}
+	}
+	if _, err = proxyNames.GetOne(ctx, api, id); !errors.Is(err, zapi.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	if err = proxyNames.DeleteByIDs(ctx, api, []string{id}); !errors.Is(err, zapi.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestResourceWithoutID(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic")
+		}
+	}()
+	r := &zapi.Resource[struct{ Name string }]{Object: "host", IDField: "hostid"}
+	r.ID(&struct{ Name string }{})
+}
+
+// item prototypes are deleted under a key other than their ids key
+func TestResourceDeleteKey(t *testing.T) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	hosts := zapi.Hosts{{Host: "web"}}
+	if err = api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	protos := zapi.Items{
+		{HostID: hosts[0].HostID, Key: "vfs.fs.size[{#FSNAME},pfree]", Name: "Free on {#FSNAME}", Type: zapi.ZabbixTrapper},
+		{HostID: hosts[0].HostID, Key: "vfs.fs.size[{#FSNAME},used]", Name: "Used on {#FSNAME}", Type: zapi.ZabbixTrapper},
+	}
+	if err = api.ProtoItemsCreate(protos); err != nil {
+		t.Fatal(err)
+	}
+	if protos[0].ItemID == "" || protos[1].ItemID == "" {
+		t.Fatalf("Ids not set: %#v", protos)
+	}
+
+	if err = api.ProtoItemsDelete(protos[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if protos[0].ItemID != "" {
+		t.Errorf("Id not cleared: %#v", protos[0])
+	}
+	if err = api.ProtoItemsDeleteByIds([]string{protos[1].ItemID}); err != nil {
+		t.Fatal(err)
+	}
+	if left, err := api.ProtoItemsGet(zapi.Params{}); err != nil || len(left) != 0 {
+		t.Errorf("Expected no prototypes, got %#v, %v", left, err)
+	}
+}
@@ -0,0 +1,126 @@
+package zabbix
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpErrorExcerpt is the maximum number of body bytes kept in HTTPError
+const httpErrorExcerpt = 512
+
+// HTTPError is returned when the frontend answers with a status other than 200,
+// typically a proxy or PHP error page instead of a JSON-RPC response.
+type HTTPError struct {
+	StatusCode int
+	Status     string
+	// Body is the beginning of the response body
+	Body string
+}
+
+func newHTTPError(res *http.Response, body []byte) *HTTPError {
+	if len(body) > httpErrorExcerpt {
+		body = body[:httpErrorExcerpt]
+	}
+	return &HTTPError{
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+		Body:       string(body),
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %s: %s", e.Status, e.Body)
+}
+
+// RetryPolicy controls how failed HTTP exchanges are retried.
+// Transport errors and RetryableStatus responses are retried with exponential backoff and full jitter.
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, values below 2 disable retries
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry, doubled on each attempt (100ms if zero)
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff (5s if zero)
+	MaxDelay time.Duration
+	// RetryableStatus lists the HTTP status codes worth retrying (502, 503 and 504 if nil)
+	RetryableStatus []int
+	// AllMethods also retries non idempotent methods such as *.create,
+	// by default only *.get and apiinfo.version calls are retried
+	AllMethods bool
+}
+
+var defaultRetryableStatus = []int{
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// isIdempotent reports whether method only reads data and can be safely sent twice.
+func isIdempotent(method string) bool {
+	method = strings.ToLower(method)
+	return strings.HasSuffix(method, ".get") || method == "apiinfo.version"
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	e, ok := err.(*HTTPError)
+	if !ok {
+		// transport error
+		return true
+	}
+	codes := p.RetryableStatus
+	if codes == nil {
+		codes = defaultRetryableStatus
+	}
+	for _, c := range codes {
+		if c == e.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	base, max := p.BaseDelay, p.MaxDelay
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 5 * time.Second
+	}
+	d := max
+	if attempt < 32 && base<<uint(attempt) > 0 && base<<uint(attempt) < max {
+		d = base << uint(attempt)
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// send posts body, retrying according to Config.Retry, and returns the url of the endpoint that answered.
+// Calls that are not idempotent are only retried if the policy allows all methods.
+func (api *API) send(ctx context.Context, idempotent bool, body []byte, bearer string) (b []byte, url string, err error) {
+	p := &api.Config.Retry
+	attempts := p.MaxAttempts
+	if attempts < 1 || (!idempotent && !p.AllMethods) {
+		attempts = 1
+	}
+
+	for attempt := 0; ; attempt++ {
+		b, url, err = api.postAny(ctx, idempotent, body, bearer)
+		if err == nil || attempt+1 >= attempts || ctx.Err() != nil || !p.retryable(err) {
+			return
+		}
+
+		delay := p.backoff(attempt)
+		api.printf("Retrying in %s after: %s", delay, err)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return b, url, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
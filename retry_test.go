@@ -0,0 +1,74 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+// flakyServer answers the first failures calls after the version probe with status
+func flakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			ID     int32  `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Method == "APIInfo.version" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","result":"5.0.0","id":%d}`, req.ID)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) <= failures {
+			w.WriteHeader(status)
+			fmt.Fprint(w, "<html>Bad gateway</html>")
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","result":[],"id":%d}`, req.ID)
+	}))
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+	srv, calls := flakyServer(2, http.StatusBadGateway)
+	defer srv.Close()
+
+	retry := zapi.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Retry: retry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(calls); n != 3 {
+		t.Errorf("Expected 3 attempts, got %d", n)
+	}
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	srv, calls := flakyServer(1, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	retry := zapi.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Retry: retry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = api.HostsCreate(zapi.Hosts{{Host: "h"}})
+	e, ok := err.(*zapi.HTTPError)
+	if !ok || e.StatusCode != http.StatusServiceUnavailable || e.Body != "<html>Bad gateway</html>" {
+		t.Fatalf("Expected HTTP error, got %#v", err)
+	}
+	if n := atomic.LoadInt32(calls); n != 1 {
+		t.Errorf("Expected 1 attempt, got %d", n)
+	}
+}
@@ -0,0 +1,150 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultSenderPort is the trapper port of Zabbix servers and proxies
+	defaultSenderPort = "10051"
+	// defaultSenderBatch is the number of values per connection, as in zabbix_sender
+	defaultSenderBatch = 250
+	// defaultSenderTimeout bounds each connection
+	defaultSenderTimeout = 30 * time.Second
+)
+
+// SenderConfig configures a Sender.
+type SenderConfig struct {
+	// Addr is the host:port of the Zabbix server or proxy, port 10051 if missing
+	Addr string
+	// Timeout bounds each connection, 30s if zero
+	Timeout time.Duration
+	// Compress compresses requests, supported by Zabbix 4.0 and later
+	Compress bool
+	// BatchSize is the number of values sent per connection, 250 if zero
+	BatchSize int
+	TLSOptions
+}
+
+// SenderValue is a value sent to a trapper item
+type SenderValue struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	// Clock and Ns are the time of the value, the time it is received if Clock is zero
+	Clock int64 `json:"clock,omitempty"`
+	Ns    int64 `json:"ns,omitempty"`
+}
+
+// SenderResult is the outcome reported by the server, summed over batches
+type SenderResult struct {
+	Processed    int
+	Failed       int
+	Total        int
+	SecondsSpent float64
+}
+
+// Sender pushes values to ZabbixTrapper items like zabbix_sender, with the ZBXD protocol.
+// https://www.zabbix.com/documentation/current/en/manual/appendix/protocols/zabbix_sender
+type Sender struct {
+	config SenderConfig
+	addr   string
+}
+
+// NewSender Creates a Sender, checking its TLS options.
+func NewSender(c SenderConfig) (*Sender, error) {
+	if err := c.TLSOptions.check(); err != nil {
+		return nil, err
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultSenderTimeout
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultSenderBatch
+	}
+	return &Sender{config: c, addr: withDefaultPort(c.Addr, defaultSenderPort)}, nil
+}
+
+// SenderError is returned when the server rejects a request as a whole
+type SenderError struct {
+	Response string
+	Info     string
+}
+
+func (e *SenderError) Error() string {
+	return fmt.Sprintf("sender data: %s: %s", e.Response, e.Info)
+}
+
+// senderInfo matches the info of sender responses
+var senderInfo = regexp.MustCompile(`processed: (\d+); failed: (\d+); total: (\d+); seconds spent: ([0-9.]+)`)
+
+// parseSenderResponse decodes {"response":"success","info":"processed: 1; failed: 0; total: 1; seconds spent: 0.000055"}
+func parseSenderResponse(b []byte) (res SenderResult, err error) {
+	var raw struct {
+		Response string `json:"response"`
+		Info     string `json:"info"`
+	}
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return res, fmt.Errorf("bad sender response %q: %w", b, err)
+	}
+	if raw.Response != "success" {
+		return res, &SenderError{raw.Response, raw.Info}
+	}
+	m := senderInfo.FindStringSubmatch(raw.Info)
+	if m == nil {
+		return res, fmt.Errorf("bad sender response info %q", raw.Info)
+	}
+	res.Processed, _ = strconv.Atoi(m[1])
+	res.Failed, _ = strconv.Atoi(m[2])
+	res.Total, _ = strconv.Atoi(m[3])
+	res.SecondsSpent, _ = strconv.ParseFloat(m[4], 64)
+	return
+}
+
+// Send Sends values in batches of SenderConfig.BatchSize, one connection per batch.
+// On error the result covers the batches sent before.
+func (s *Sender) Send(values []SenderValue) (SenderResult, error) {
+	return s.SendContext(context.Background(), values)
+}
+
+// SendContext is like Send but uses ctx for the connections.
+func (s *Sender) SendContext(ctx context.Context, values []SenderValue) (res SenderResult, err error) {
+	for start := 0; start < len(values); start += s.config.BatchSize {
+		end := start + s.config.BatchSize
+		if end > len(values) {
+			end = len(values)
+		}
+		var batch SenderResult
+		if batch, err = s.send(ctx, values[start:end]); err != nil {
+			return
+		}
+		res.Processed += batch.Processed
+		res.Failed += batch.Failed
+		res.Total += batch.Total
+		res.SecondsSpent += batch.SecondsSpent
+	}
+	return
+}
+
+func (s *Sender) send(ctx context.Context, values []SenderValue) (res SenderResult, err error) {
+	now := time.Now()
+	request, err := json.Marshal(struct {
+		Request string        `json:"request"`
+		Data    []SenderValue `json:"data"`
+		Clock   int64         `json:"clock"`
+		Ns      int64         `json:"ns"`
+	}{"sender data", values, now.Unix(), int64(now.Nanosecond())})
+	if err != nil {
+		return
+	}
+	response, err := s.config.exchange(ctx, s.addr, s.config.Timeout, request, s.config.Compress)
+	if err != nil {
+		return
+	}
+	return parseSenderResponse(response)
+}
@@ -0,0 +1,209 @@
+package zabbix_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+// zbxdServer is a TCP stand-in answering each ZBXD request with reply, it returns the received requests
+func zbxdServer(t *testing.T, reply func(request []byte) []byte, compress bool) (string, func() [][]byte) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	var mu sync.Mutex
+	var requests [][]byte
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			request, err := readZBXD(conn)
+			if err != nil {
+				t.Error(err)
+				conn.Close()
+				continue
+			}
+			mu.Lock()
+			requests = append(requests, request)
+			mu.Unlock()
+			if err = writeZBXD(conn, reply(request), compress); err != nil {
+				t.Error(err)
+			}
+			conn.Close()
+		}
+	}()
+	return l.Addr().String(), func() [][]byte {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func readZBXD(r io.Reader) ([]byte, error) {
+	var header [13]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[:4]) != "ZBXD" {
+		return nil, fmt.Errorf("bad header %q", header)
+	}
+	data := make([]byte, binary.LittleEndian.Uint32(header[5:]))
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if header[4]&0x02 == 0 {
+		return data, nil
+	}
+	z, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	data, err = io.ReadAll(z)
+	if err == nil && len(data) != int(binary.LittleEndian.Uint32(header[9:])) {
+		err = fmt.Errorf("uncompressed size %d, header says %d", len(data), binary.LittleEndian.Uint32(header[9:]))
+	}
+	return data, err
+}
+
+func writeZBXD(w io.Writer, data []byte, compress bool) error {
+	header := []byte{'Z', 'B', 'X', 'D', 0x01, 0, 0, 0, 0, 0, 0, 0, 0}
+	if compress {
+		var buf bytes.Buffer
+		z := zlib.NewWriter(&buf)
+		z.Write(data)
+		z.Close()
+		header[4] |= 0x02
+		binary.LittleEndian.PutUint32(header[9:], uint32(len(data)))
+		data = buf.Bytes()
+	}
+	binary.LittleEndian.PutUint32(header[5:], uint32(len(data)))
+	_, err := w.Write(append(header, data...))
+	return err
+}
+
+type senderRequest struct {
+	Request string             `json:"request"`
+	Data    []zapi.SenderValue `json:"data"`
+	Clock   int64              `json:"clock"`
+}
+
+func senderReply(t *testing.T, failed int) func([]byte) []byte {
+	return func(b []byte) []byte {
+		var r senderRequest
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Error(err)
+		}
+		return []byte(fmt.Sprintf(`{"response":"success","info":"processed: %d; failed: %d; total: %d; seconds spent: 0.000250"}`,
+			len(r.Data)-failed, failed, len(r.Data)))
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	addr, requests := zbxdServer(t, senderReply(t, 1), false)
+	s, err := zapi.NewSender(zapi.SenderConfig{Addr: addr, BatchSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := []zapi.SenderValue{
+		{Host: "web", Key: "trap[a]", Value: "1", Clock: 1700000000, Ns: 5},
+		{Host: "web", Key: "trap[b]", Value: "ok"},
+		{Host: "db", Key: "trap[a]", Value: "3.5"},
+	}
+	res, err := s.Send(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := zapi.SenderResult{Processed: 1, Failed: 2, Total: 3, SecondsSpent: 0.0005}
+	if res != expected {
+		t.Errorf("Expected %+v, got %+v", expected, res)
+	}
+
+	sent := requests()
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 batches, got %d", len(sent))
+	}
+	var first senderRequest
+	if err = json.Unmarshal(sent[0], &first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Request != "sender data" || first.Clock == 0 || len(first.Data) != 2 || first.Data[0] != values[0] {
+		t.Errorf("Bad request: %s", sent[0])
+	}
+	if !bytes.Contains(sent[0], []byte(`{"host":"web","key":"trap[b]","value":"ok"}`)) {
+		t.Errorf("Clock of values without one must be omitted: %s", sent[0])
+	}
+}
+
+func TestSenderCompressed(t *testing.T) {
+	addr, requests := zbxdServer(t, senderReply(t, 0), true)
+	s, err := zapi.NewSender(zapi.SenderConfig{Addr: addr, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := s.SendContext(context.Background(), []zapi.SenderValue{{Host: "web", Key: "trap", Value: "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Processed != 1 || res.Total != 1 {
+		t.Errorf("Bad result: %+v", res)
+	}
+	if len(requests()) != 1 {
+		t.Errorf("Expected 1 request, got %d", len(requests()))
+	}
+}
+
+func TestSenderErrors(t *testing.T) {
+	addr, _ := zbxdServer(t, func([]byte) []byte {
+		return []byte(`{"response":"failed","info":"cannot parse request"}`)
+	}, false)
+	s, err := zapi.NewSender(zapi.SenderConfig{Addr: addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Send([]zapi.SenderValue{{Host: "web", Key: "trap", Value: "1"}})
+	var e *zapi.SenderError
+	if !errors.As(err, &e) || e.Info != "cannot parse request" {
+		t.Errorf("Expected a SenderError, got %v", err)
+	}
+
+	psk := zapi.TLSOptions{TLSConnect: zapi.TLSPSK, TLSPSKIdentity: "id", TLSPSK: "00112233445566778899aabbccddeeff"}
+	if _, err = zapi.NewSender(zapi.SenderConfig{Addr: addr, TLSOptions: psk}); !errors.Is(err, zapi.ErrPSKUnsupported) {
+		t.Errorf("Expected ErrPSKUnsupported, got %v", err)
+	}
+	psk.TLSPSK = "0011"
+	if _, err = zapi.NewSender(zapi.SenderConfig{Addr: addr, TLSOptions: psk}); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+
+	// PSKDial is used for PSK connections
+	dialed := ""
+	psk.TLSPSK = "00112233445566778899aabbccddeeff"
+	psk.PSKDial = func(ctx context.Context, addr, identity string, key []byte) (net.Conn, error) {
+		dialed = identity
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	if s, err = zapi.NewSender(zapi.SenderConfig{Addr: addr, TLSOptions: psk}); err != nil {
+		t.Fatal(err)
+	}
+	s.Send([]zapi.SenderValue{{Host: "web", Key: "trap", Value: "1"}})
+	if dialed != "id" {
+		t.Errorf("PSKDial not used, dialed %q", dialed)
+	}
+}
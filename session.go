@@ -0,0 +1,62 @@
+package zabbix
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// CredentialsFunc returns the user and password used to log in again once a session expired.
+type CredentialsFunc func(ctx context.Context) (user, password string, err error)
+
+var errNoCredentials = errors.New("no credentials available for relogin")
+
+// withRelogin runs call, and runs it a second time after logging in again
+// if it failed on an expired session and Config.Relogin is set.
+func (api *API) withRelogin(ctx context.Context, method string, call func() error) error {
+	auth := api.getAuth()
+	err := call()
+	if !api.Config.Relogin || !errors.Is(err, ErrSessionExpired) {
+		return err
+	}
+	switch strings.ToLower(method) {
+	case "user.login", "apiinfo.version":
+		return err
+	}
+
+	if lerr := api.relogin(ctx, auth); lerr != nil {
+		api.printf("Relogin failed: %s", lerr)
+		return err
+	}
+	return call()
+}
+
+// relogin logs in again unless another goroutine already replaced the stale session.
+func (api *API) relogin(ctx context.Context, stale string) (err error) {
+	api.reloginMu.Lock()
+	defer api.reloginMu.Unlock()
+
+	if api.getAuth() != stale {
+		return
+	}
+
+	user, password := api.user, api.password
+	if api.Config.Credentials != nil {
+		user, password, err = api.Config.Credentials(ctx)
+		if err != nil {
+			return
+		}
+	}
+	if user == "" {
+		return errNoCredentials
+	}
+
+	api.setAuth("")
+	if _, err = api.login(ctx, user, password); err != nil {
+		// keep the stale session so the caller sees the original error again
+		api.setAuth(stale)
+		return
+	}
+	api.user, api.password = user, password
+	return
+}
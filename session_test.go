@@ -0,0 +1,128 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestRelogin(t *testing.T) {
+	var logins int32
+	var mu sync.Mutex
+	valid := ""
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if method == "user.login" {
+			valid = fmt.Sprintf("session-%d", atomic.AddInt32(&logins, 1))
+			return valid, nil
+		}
+		if auth != valid {
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Session terminated, re-login, please."}
+		}
+		return []interface{}{}, nil
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Relogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login("Admin", "zabbix"); err != nil {
+		t.Fatal(err)
+	}
+
+	// expire the session on the server side
+	mu.Lock()
+	valid = "expired"
+	mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := api.HostsGet(zapi.Params{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&logins); n != 2 {
+		t.Errorf("Expected 2 logins, got %d", n)
+	}
+}
+
+func TestReloginDisabled(t *testing.T) {
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		if method == "user.login" {
+			t.Error("Unexpected login")
+		}
+		return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Session terminated, re-login, please."}
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.Auth = "stale"
+	if _, err = api.HostsGet(zapi.Params{}); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestReloginConcurrentLogin(t *testing.T) {
+	var mu sync.Mutex
+	logins, valid := 0, ""
+	srv := newRPCServer(t, "5.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if method == "user.login" {
+			logins++
+			valid = fmt.Sprintf("session-%d", logins)
+			return valid, nil
+		}
+		if auth != valid {
+			return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: "Session terminated, re-login, please."}
+		}
+		// each session serves a single call
+		valid = "expired"
+		return []interface{}{}, nil
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Relogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login("Admin", "zabbix"); err != nil {
+		t.Fatal(err)
+	}
+
+	// relogins read the credentials while Login stores them, go test -race checks the access
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if i == 0 {
+					if _, err := api.Login("Admin", "zabbix"); err != nil {
+						t.Error(err)
+					}
+				} else {
+					// another goroutine may expire the new session before the call is sent again
+					api.HostsGet(zapi.Params{})
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
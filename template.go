@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 // Template represent Zabbix Template type returned from Zabbix API
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/object
 type Template struct {
@@ -18,6 +20,12 @@ type Template struct {
 // Templates is an Array of Template structs.
 type Templates []Template
 
+// TemplateResource describes template objects
+var TemplateResource = &Resource[Template]{
+	Object:  "template",
+	IDField: "templateid",
+}
+
 // TemplateID use with host creation
 type TemplateID struct {
 	TemplateID string `json:"templateid"`
@@ -29,83 +37,76 @@ type TemplateIDs []TemplateID
 // TemplatesGet Wrapper for template.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/get
 func (api *API) TemplatesGet(params Params) (res Templates, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("template.get", params, &res)
-	return
+	return api.TemplatesGetContext(context.Background(), params)
+}
+
+// TemplatesGetContext is like TemplatesGet but uses ctx for the request.
+func (api *API) TemplatesGetContext(ctx context.Context, params Params) (res Templates, err error) {
+	return TemplateResource.Get(ctx, api, params)
+}
+
+// TemplatesQuery Wrapper for template.get taking typed options instead of Params.
+func (api *API) TemplatesQuery(q TemplateQuery) (res Templates, err error) {
+	return api.TemplatesQueryContext(context.Background(), q)
+}
+
+// TemplatesQueryContext is like TemplatesQuery but uses ctx for the request.
+func (api *API) TemplatesQueryContext(ctx context.Context, q TemplateQuery) (res Templates, err error) {
+	return TemplateResource.Query(ctx, api, q)
 }
 
 // TemplateGetByID Gets template by Id only if there is exactly 1 matching template.
 func (api *API) TemplateGetByID(id string) (template *Template, err error) {
-	templates, err := api.TemplatesGet(Params{"templateids": id})
-	if err != nil {
-		return
-	}
+	return api.TemplateGetByIDContext(context.Background(), id)
+}
 
-	if len(templates) == 1 {
-		template = &templates[0]
-	} else {
-		e := ExpectedOneResult(len(templates))
-		err = &e
-	}
-	return
+// TemplateGetByIDContext is like TemplateGetByID but uses ctx for the request.
+func (api *API) TemplateGetByIDContext(ctx context.Context, id string) (template *Template, err error) {
+	return TemplateResource.GetOne(ctx, api, id)
 }
 
 // TemplatesCreate Wrapper for template.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/create
 func (api *API) TemplatesCreate(templates Templates) (err error) {
-	response, err := api.CallWithError("template.create", templates)
-	if err != nil {
-		return
-	}
+	return api.TemplatesCreateContext(context.Background(), templates)
+}
 
-	result := response.Result.(map[string]interface{})
-	templateids := result["templateids"].([]interface{})
-	for i, id := range templateids {
-		templates[i].TemplateID = id.(string)
-	}
-	return
+// TemplatesCreateContext is like TemplatesCreate but uses ctx for the request.
+func (api *API) TemplatesCreateContext(ctx context.Context, templates Templates) (err error) {
+	return TemplateResource.Create(ctx, api, templates)
 }
 
 // TemplatesUpdate Wrapper for template.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/update
 func (api *API) TemplatesUpdate(templates Templates) (err error) {
-	_, err = api.CallWithError("template.update", templates)
-	return
+	return api.TemplatesUpdateContext(context.Background(), templates)
+}
+
+// TemplatesUpdateContext is like TemplatesUpdate but uses ctx for the request.
+func (api *API) TemplatesUpdateContext(ctx context.Context, templates Templates) (err error) {
+	return TemplateResource.Update(ctx, api, templates)
 }
 
 // TemplatesDelete Wrapper for template.delete
 // Cleans ApplicationID in all apps elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/delete
 func (api *API) TemplatesDelete(templates Templates) (err error) {
-	templatesIds := make([]string, len(templates))
-	for i, template := range templates {
-		templatesIds[i] = template.TemplateID
-	}
+	return api.TemplatesDeleteContext(context.Background(), templates)
+}
 
-	err = api.TemplatesDeleteByIds(templatesIds)
-	if err == nil {
-		for i := range templates {
-			templates[i].TemplateID = ""
-		}
-	}
-	return
+// TemplatesDeleteContext is like TemplatesDelete but uses ctx for the request.
+func (api *API) TemplatesDeleteContext(ctx context.Context, templates Templates) (err error) {
+	return TemplateResource.Delete(ctx, api, templates)
 }
 
 // TemplatesDeleteByIds Wrapper for template.delete
 // Use template's id to delete the template
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/template/delete
 func (api *API) TemplatesDeleteByIds(ids []string) (err error) {
-	response, err := api.CallWithError("template.delete", ids)
-	if err != nil {
-		return
-	}
-
-	result := response.Result.(map[string]interface{})
-	templateids := result["templateids"].([]interface{})
-	if len(ids) != len(templateids) {
-		err = &ExpectedMore{len(ids), len(templateids)}
-	}
-	return
+	return api.TemplatesDeleteByIdsContext(context.Background(), ids)
+}
+
+// TemplatesDeleteByIdsContext is like TemplatesDeleteByIds but uses ctx for the request.
+func (api *API) TemplatesDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return TemplateResource.DeleteByIDs(ctx, api, ids)
 }
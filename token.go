@@ -0,0 +1,114 @@
+package zabbix
+
+import "context"
+
+// Token represent Zabbix API token object
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/object
+type Token struct {
+	TokenID       string     `json:"tokenid,omitempty"`
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	UserID        string     `json:"userid,omitempty"`
+	Status        StatusType `json:"status,string"`
+	ExpiresAt     int64      `json:"expires_at,string,omitempty"`
+	LastAccess    int64      `json:"lastaccess,string,omitempty"`
+	CreatedAt     int64      `json:"created_at,string,omitempty"`
+	CreatorUserID string     `json:"creator_userid,omitempty"`
+}
+
+// Tokens is an array of Token
+type Tokens []Token
+
+// TokenResource describes token objects
+var TokenResource = &Resource[Token]{
+	Object:  "token",
+	IDField: "tokenid",
+}
+
+// GeneratedToken holds the secret returned by token.generate
+type GeneratedToken struct {
+	TokenID string `json:"tokenid"`
+	Token   string `json:"token"`
+}
+
+// GeneratedTokens is an array of GeneratedToken
+type GeneratedTokens []GeneratedToken
+
+// TokensGet Wrapper for token.get
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/get
+func (api *API) TokensGet(params Params) (res Tokens, err error) {
+	return api.TokensGetContext(context.Background(), params)
+}
+
+// TokensGetContext is like TokensGet but uses ctx for the request.
+func (api *API) TokensGetContext(ctx context.Context, params Params) (res Tokens, err error) {
+	return TokenResource.Get(ctx, api, params)
+}
+
+// TokenGetByID Gets token by Id only if there is exactly 1 matching token.
+func (api *API) TokenGetByID(id string) (res *Token, err error) {
+	return api.TokenGetByIDContext(context.Background(), id)
+}
+
+// TokenGetByIDContext is like TokenGetByID but uses ctx for the request.
+func (api *API) TokenGetByIDContext(ctx context.Context, id string) (res *Token, err error) {
+	return TokenResource.GetOne(ctx, api, id)
+}
+
+// TokensCreate Wrapper for token.create
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/create
+func (api *API) TokensCreate(tokens Tokens) (err error) {
+	return api.TokensCreateContext(context.Background(), tokens)
+}
+
+// TokensCreateContext is like TokensCreate but uses ctx for the request.
+func (api *API) TokensCreateContext(ctx context.Context, tokens Tokens) (err error) {
+	return TokenResource.Create(ctx, api, tokens)
+}
+
+// TokensUpdate Wrapper for token.update
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/update
+func (api *API) TokensUpdate(tokens Tokens) (err error) {
+	return api.TokensUpdateContext(context.Background(), tokens)
+}
+
+// TokensUpdateContext is like TokensUpdate but uses ctx for the request.
+func (api *API) TokensUpdateContext(ctx context.Context, tokens Tokens) (err error) {
+	return TokenResource.Update(ctx, api, tokens)
+}
+
+// TokensDelete Wrapper for token.delete
+// Cleans TokenID in all tokens elements if call succeed.
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/delete
+func (api *API) TokensDelete(tokens Tokens) (err error) {
+	return api.TokensDeleteContext(context.Background(), tokens)
+}
+
+// TokensDeleteContext is like TokensDelete but uses ctx for the request.
+func (api *API) TokensDeleteContext(ctx context.Context, tokens Tokens) (err error) {
+	return TokenResource.Delete(ctx, api, tokens)
+}
+
+// TokensDeleteByIds Wrapper for token.delete
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/delete
+func (api *API) TokensDeleteByIds(ids []string) (err error) {
+	return api.TokensDeleteByIdsContext(context.Background(), ids)
+}
+
+// TokensDeleteByIdsContext is like TokensDeleteByIds but uses ctx for the request.
+func (api *API) TokensDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return TokenResource.DeleteByIDs(ctx, api, ids)
+}
+
+// TokensGenerate Wrapper for token.generate
+// Returns the secrets of the given tokens, they cannot be read back later.
+// https://www.zabbix.com/documentation/5.4/manual/api/reference/token/generate
+func (api *API) TokensGenerate(ids []string) (res GeneratedTokens, err error) {
+	return api.TokensGenerateContext(context.Background(), ids)
+}
+
+// TokensGenerateContext is like TokensGenerate but uses ctx for the request.
+func (api *API) TokensGenerateContext(ctx context.Context, ids []string) (res GeneratedTokens, err error) {
+	err = api.CallWithErrorParseContext(ctx, "token.generate", ids, &res)
+	return
+}
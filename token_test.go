@@ -0,0 +1,81 @@
+package zabbix_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestTokenAuthPlacement(t *testing.T) {
+	for _, tc := range []struct {
+		version    string
+		wantHeader string
+		wantBody   string
+	}{
+		{"5.4.0", "", "secret"},
+		{"6.4.0", "Bearer secret", ""},
+	} {
+		var header, body string
+		srv := newRPCServer(t, tc.version, func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+			header, body = r.Header.Get("Authorization"), auth
+			return []interface{}{}, nil
+		})
+
+		api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Token: "secret"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = api.HostsGet(zapi.Params{}); err != nil {
+			t.Fatal(err)
+		}
+		if header != tc.wantHeader || body != tc.wantBody {
+			t.Errorf("%s: got header %q and body auth %q", tc.version, header, body)
+		}
+		srv.Close()
+	}
+}
+
+func TestTokens(t *testing.T) {
+	srv := newRPCServer(t, "6.0.0", func(r *http.Request, method string, params json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		switch method {
+		case "token.create":
+			return map[string][]string{"tokenids": {"7"}}, nil
+		case "token.generate":
+			return []map[string]string{{"tokenid": "7", "token": "abc"}}, nil
+		case "token.delete":
+			return map[string][]string{"tokenids": {"7"}}, nil
+		}
+		return nil, &zapi.Error{Code: -32601, Message: "Method not found."}
+	})
+	defer srv.Close()
+
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := zapi.Tokens{{Name: "ci", UserID: "1"}}
+	if err = api.TokensCreate(tokens); err != nil {
+		t.Fatal(err)
+	}
+	if tokens[0].TokenID != "7" {
+		t.Errorf("Bad token id: %#v", tokens[0])
+	}
+
+	gen, err := api.TokensGenerate([]string{tokens[0].TokenID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gen) != 1 || gen[0].Token != "abc" {
+		t.Errorf("Bad generated tokens: %#v", gen)
+	}
+
+	if err = api.TokensDelete(tokens); err != nil {
+		t.Fatal(err)
+	}
+	if tokens[0].TokenID != "" {
+		t.Errorf("Token id not cleared: %#v", tokens[0])
+	}
+}
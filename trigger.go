@@ -1,5 +1,7 @@
 package zabbix
 
+import "context"
+
 type (
 	// SeverityType of a trigger
 	// Zabbix severity see : https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/object
@@ -97,185 +99,178 @@ type Trigger struct {
 // Triggers is an array of Trigger
 type Triggers []Trigger
 
+// TriggerResource describes trigger objects
+var TriggerResource = &Resource[Trigger]{
+	Object:  "trigger",
+	IDField: "triggerid",
+}
+
+// TriggerPrototypeResource describes triggerprototype objects
+var TriggerPrototypeResource = &Resource[Trigger]{
+	Object:  "triggerprototype",
+	IDField: "triggerid",
+}
+
 // TriggersGet Wrapper for trigger.get
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/get
 func (api *API) TriggersGet(params Params) (res Triggers, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("trigger.get", params, &res)
-	return
+	return api.TriggersGetContext(context.Background(), params)
+}
+
+// TriggersGetContext is like TriggersGet but uses ctx for the request.
+func (api *API) TriggersGetContext(ctx context.Context, params Params) (res Triggers, err error) {
+	return TriggerResource.Get(ctx, api, params)
+}
+
+// TriggersQuery Wrapper for trigger.get taking typed options instead of Params.
+func (api *API) TriggersQuery(q TriggerQuery) (res Triggers, err error) {
+	return api.TriggersQueryContext(context.Background(), q)
+}
+
+// TriggersQueryContext is like TriggersQuery but uses ctx for the request.
+func (api *API) TriggersQueryContext(ctx context.Context, q TriggerQuery) (res Triggers, err error) {
+	return TriggerResource.Query(ctx, api, q)
+}
+
+// TriggersGetPages Wrapper for trigger.get returning the results page by page, see PageOptions.
+// fn is called for each page, an error returned by fn stops the pagination and is returned.
+func (api *API) TriggersGetPages(ctx context.Context, params Params, opts PageOptions, fn func(Triggers) error) error {
+	return TriggerResource.GetPages(ctx, api, params, opts, func(triggers []Trigger) error {
+		return fn(triggers)
+	})
+}
+
+// TriggersGetStream is like TriggersGetPages but delivers triggers one by one on a channel.
+// The error channel is closed once all triggers are delivered, after receiving the error that stopped the stream if any.
+func (api *API) TriggersGetStream(ctx context.Context, params Params, opts PageOptions) (<-chan Trigger, <-chan error) {
+	return TriggerResource.GetStream(ctx, api, params, opts)
 }
 func (api *API) ProtoTriggersGet(params Params) (res Triggers, err error) {
-	if _, present := params["output"]; !present {
-		params["output"] = "extend"
-	}
-	err = api.CallWithErrorParse("triggerprototype.get", params, &res)
-	return
+	return api.ProtoTriggersGetContext(context.Background(), params)
+}
+
+func (api *API) ProtoTriggersGetContext(ctx context.Context, params Params) (res Triggers, err error) {
+	return TriggerPrototypeResource.Get(ctx, api, params)
 }
 
 // TriggerGetByID Gets trigger by Id only if there is exactly 1 matching host.
 func (api *API) TriggerGetByID(id string) (res *Trigger, err error) {
-	triggers, err := api.TriggersGet(Params{"triggerids": id})
-	if err != nil {
-		return
-	}
+	return api.TriggerGetByIDContext(context.Background(), id)
+}
 
-	if len(triggers) != 1 {
-		e := ExpectedOneResult(len(triggers))
-		err = &e
-		return
-	}
-	res = &triggers[0]
-	return
+// TriggerGetByIDContext is like TriggerGetByID but uses ctx for the request.
+func (api *API) TriggerGetByIDContext(ctx context.Context, id string) (res *Trigger, err error) {
+	return TriggerResource.GetOne(ctx, api, id)
 }
 func (api *API) ProtoTriggerGetByID(id string) (res *Trigger, err error) {
-	triggers, err := api.ProtoTriggersGet(Params{"triggerids": id})
-	if err != nil {
-		return
-	}
+	return api.ProtoTriggerGetByIDContext(context.Background(), id)
+}
 
-	if len(triggers) != 1 {
-		e := ExpectedOneResult(len(triggers))
-		err = &e
-		return
-	}
-	res = &triggers[0]
-	return
+func (api *API) ProtoTriggerGetByIDContext(ctx context.Context, id string) (res *Trigger, err error) {
+	return TriggerPrototypeResource.GetOne(ctx, api, id)
 }
 
 // TriggersCreate Wrapper for trigger.create
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/create
 func (api *API) TriggersCreate(triggers Triggers) (err error) {
-	response, err := api.CallWithError("trigger.create", triggers)
-	if err != nil {
-		return
-	}
+	return api.TriggersCreateContext(context.Background(), triggers)
+}
 
-	result := response.Result.(map[string]interface{})
-	triggerids := result["triggerids"].([]interface{})
-	for i, id := range triggerids {
-		triggers[i].TriggerID = id.(string)
-	}
-	return
+// TriggersCreateContext is like TriggersCreate but uses ctx for the request.
+func (api *API) TriggersCreateContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerResource.Create(ctx, api, triggers)
 }
 func (api *API) ProtoTriggersCreate(triggers Triggers) (err error) {
-	response, err := api.CallWithError("triggerprototype.create", triggers)
-	if err != nil {
-		return
-	}
+	return api.ProtoTriggersCreateContext(context.Background(), triggers)
+}
 
-	result := response.Result.(map[string]interface{})
-	triggerids := result["triggerids"].([]interface{})
-	for i, id := range triggerids {
-		triggers[i].TriggerID = id.(string)
-	}
-	return
+func (api *API) ProtoTriggersCreateContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerPrototypeResource.Create(ctx, api, triggers)
 }
 
 // TriggersUpdate Wrapper for trigger.update
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/update
 func (api *API) TriggersUpdate(triggers Triggers) (err error) {
-	_, err = api.CallWithError("trigger.update", triggers)
-	return
+	return api.TriggersUpdateContext(context.Background(), triggers)
+}
+
+// TriggersUpdateContext is like TriggersUpdate but uses ctx for the request.
+func (api *API) TriggersUpdateContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerResource.Update(ctx, api, triggers)
 }
 func (api *API) ProtoTriggersUpdate(triggers Triggers) (err error) {
-	_, err = api.CallWithError("triggerprototype.update", triggers)
-	return
+	return api.ProtoTriggersUpdateContext(context.Background(), triggers)
+}
+
+func (api *API) ProtoTriggersUpdateContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerPrototypeResource.Update(ctx, api, triggers)
 }
 
 // TriggersDelete Wrapper for trigger.delete
 // Cleans ItemId in all triggers elements if call succeed.
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/delete
 func (api *API) TriggersDelete(triggers Triggers) (err error) {
-	ids := make([]string, len(triggers))
-	for i, trigger := range triggers {
-		ids[i] = trigger.TriggerID
-	}
+	return api.TriggersDeleteContext(context.Background(), triggers)
+}
 
-	err = api.TriggersDeleteByIds(ids)
-	if err == nil {
-		for i := range triggers {
-			triggers[i].TriggerID = ""
-		}
-	}
-	return
+// TriggersDeleteContext is like TriggersDelete but uses ctx for the request.
+func (api *API) TriggersDeleteContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerResource.Delete(ctx, api, triggers)
 }
 func (api *API) ProtoTriggersDelete(triggers Triggers) (err error) {
-	ids := make([]string, len(triggers))
-	for i, trigger := range triggers {
-		ids[i] = trigger.TriggerID
-	}
+	return api.ProtoTriggersDeleteContext(context.Background(), triggers)
+}
 
-	err = api.ProtoTriggersDeleteByIds(ids)
-	if err == nil {
-		for i := range triggers {
-			triggers[i].TriggerID = ""
-		}
-	}
-	return
+func (api *API) ProtoTriggersDeleteContext(ctx context.Context, triggers Triggers) (err error) {
+	return TriggerPrototypeResource.Delete(ctx, api, triggers)
 }
 
 // TriggersDeleteByIds Wrapper for trigger.delete
 // https://www.zabbix.com/documentation/3.2/manual/api/reference/trigger/delete
 func (api *API) TriggersDeleteByIds(ids []string) (err error) {
-	deleteIds, err := api.TriggersDeleteIDs(ids)
-	if err != nil {
-		return
-	}
-	l := len(deleteIds)
-	if len(ids) != l {
-		err = &ExpectedMore{len(ids), l}
-	}
-	return
+	return api.TriggersDeleteByIdsContext(context.Background(), ids)
+}
+
+// TriggersDeleteByIdsContext is like TriggersDeleteByIds but uses ctx for the request.
+func (api *API) TriggersDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return TriggerResource.DeleteByIDs(ctx, api, ids)
 }
 func (api *API) ProtoTriggersDeleteByIds(ids []string) (err error) {
-	deleteIds, err := api.ProtoTriggersDeleteIDs(ids)
-	if err != nil {
-		return
-	}
-	l := len(deleteIds)
-	if len(ids) != l {
-		err = &ExpectedMore{len(ids), l}
-	}
-	return
+	return api.ProtoTriggersDeleteByIdsContext(context.Background(), ids)
+}
+
+func (api *API) ProtoTriggersDeleteByIdsContext(ctx context.Context, ids []string) (err error) {
+	return TriggerPrototypeResource.DeleteByIDs(ctx, api, ids)
 }
 
 // TriggersDeleteIDs Wrapper for trigger.delete
 // return the id of the deleted trigger
 func (api *API) TriggersDeleteIDs(ids []string) (triggerids []interface{}, err error) {
-	response, err := api.CallWithError("trigger.delete", ids)
+	return api.TriggersDeleteIDsContext(context.Background(), ids)
+}
+
+// TriggersDeleteIDsContext is like TriggersDeleteIDs but uses ctx for the request.
+func (api *API) TriggersDeleteIDsContext(ctx context.Context, ids []string) (triggerids []interface{}, err error) {
+	deleted, err := api.callIDs(ctx, "trigger.delete", ids, "triggerids")
 	if err != nil {
 		return
 	}
-
-	result := response.Result.(map[string]interface{})
-	triggerids1, ok := result["triggerids"].([]interface{})
-	if !ok {
-		triggerids2 := result["triggerids"].(map[string]interface{})
-		for _, id := range triggerids2 {
-			triggerids = append(triggerids, id)
-		}
-	} else {
-		triggerids = triggerids1
+	for _, id := range deleted {
+		triggerids = append(triggerids, id)
 	}
 	return
 }
 func (api *API) ProtoTriggersDeleteIDs(ids []string) (triggerids []interface{}, err error) {
-	response, err := api.CallWithError("triggerprototype.delete", ids)
+	return api.ProtoTriggersDeleteIDsContext(context.Background(), ids)
+}
+
+func (api *API) ProtoTriggersDeleteIDsContext(ctx context.Context, ids []string) (triggerids []interface{}, err error) {
+	deleted, err := api.callIDs(ctx, "triggerprototype.delete", ids, "triggerids")
 	if err != nil {
 		return
 	}
-
-	result := response.Result.(map[string]interface{})
-	triggerids1, ok := result["triggerids"].([]interface{})
-	if !ok {
-		triggerids2 := result["triggerids"].(map[string]interface{})
-		for _, id := range triggerids2 {
-			triggerids = append(triggerids, id)
-		}
-	} else {
-		triggerids = triggerids1
+	for _, id := range deleted {
+		triggerids = append(triggerids, id)
 	}
 	return
 }
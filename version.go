@@ -0,0 +1,138 @@
+package zabbix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SemVer is a Zabbix server version, comparable with == and Compare.
+type SemVer struct {
+	Major, Minor, Patch int
+}
+
+// ParseSemVer parses versions such as "6.0.12" or "7.0.0rc1", missing parts are 0.
+// Suffixes after the digits of a part are ignored.
+func ParseSemVer(s string) (v SemVer, err error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ".", 3)
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		digits := strings.IndexFunc(p, func(r rune) bool { return r < '0' || r > '9' })
+		if digits < 0 {
+			digits = len(p)
+		}
+		if *nums[i], err = strconv.Atoi(p[:digits]); err != nil {
+			return SemVer{}, fmt.Errorf("bad version %q: %w", s, err)
+		}
+	}
+	return
+}
+
+// semVerOf unpacks a Config.Version, 50402 is 5.4.2
+func semVerOf(packed int) SemVer {
+	return SemVer{packed / 10000, packed / 100 % 100, packed % 100}
+}
+
+// Packed returns the version in the form of Config.Version, 5.4.2 is 50402.
+func (v SemVer) Packed() int {
+	return v.Major*10000 + v.Minor*100 + v.Patch
+}
+
+// Compare returns -1, 0 or 1 if v is older, the same or newer than o.
+func (v SemVer) Compare(o SemVer) int {
+	switch a, b := v.Packed(), o.Packed(); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast reports whether v is major.minor or newer.
+func (v SemVer) AtLeast(major, minor int) bool {
+	return v.Compare(SemVer{Major: major, Minor: minor}) >= 0
+}
+
+// IsZero reports whether the version is unknown.
+func (v SemVer) IsZero() bool {
+	return v == SemVer{}
+}
+
+func (v SemVer) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// versionMethod needs no auth and is never preceded by version detection
+const versionMethod = "apiinfo.version"
+
+// version returns the packed server version, 0 while it is unknown
+func (api *API) version() int {
+	return int(api.ver.Load())
+}
+
+// ServerVersion Returns the version of the connected server,
+// detecting it first if Config.LazyVersion delayed detection.
+func (api *API) ServerVersion() (SemVer, error) {
+	return api.ServerVersionContext(context.Background())
+}
+
+// ServerVersionContext is like ServerVersion but uses ctx for the request.
+func (api *API) ServerVersionContext(ctx context.Context) (SemVer, error) {
+	if err := api.detectVersion(ctx); err != nil {
+		return SemVer{}, err
+	}
+	return semVerOf(api.version()), nil
+}
+
+// detectVersion calls apiinfo.version unless the version is known.
+// A failed detection is retried by the next call.
+func (api *API) detectVersion(ctx context.Context) (err error) {
+	if api.version() != 0 {
+		return
+	}
+	api.verMu.Lock()
+	defer api.verMu.Unlock()
+	if api.version() != 0 {
+		return
+	}
+
+	raw, err := api.versionContext(ctx)
+	if err != nil {
+		return
+	}
+	v, err := ParseSemVer(raw)
+	if err != nil {
+		return
+	}
+	api.Config.Version = v.Packed()
+	api.ver.Store(int32(v.Packed()))
+	return
+}
+
+// versionContext calls apiinfo.version without auth,
+// or with it if the server requires it.
+func (api *API) versionContext(ctx context.Context) (v string, err error) {
+	// https://www.zabbix.com/documentation/2.2/manual/appendix/api/apiinfo/version
+	err = api.versionCall(ctx, "", &v)
+
+	// despite what documentation says, Zabbix 2.2 requires auth, so we try again
+	if errors.Is(err, ErrInvalidParams) {
+		err = api.versionCall(ctx, api.getAuth(), &v)
+	}
+	return
+}
+
+func (api *API) versionCall(ctx context.Context, auth string, v *string) (err error) {
+	res, err := api.doAuth(ctx, "APIInfo.version", Params{}, auth)
+	if err != nil {
+		return
+	}
+	if res.Error != nil {
+		return res.Error
+	}
+	return json.Unmarshal(res.Result, v)
+}
@@ -0,0 +1,147 @@
+package zabbix_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+)
+
+func TestParseSemVer(t *testing.T) {
+	for s, exp := range map[string]zapi.SemVer{
+		"6.0.12":   {6, 0, 12},
+		"5.4":      {5, 4, 0},
+		"7.0.0rc1": {7, 0, 0},
+		" 4.0.1\n": {4, 0, 1},
+	} {
+		v, err := zapi.ParseSemVer(s)
+		if err != nil {
+			t.Errorf("%q: %s", s, err)
+		} else if v != exp {
+			t.Errorf("%q: expected %s, got %s", s, exp, v)
+		}
+	}
+	for _, s := range []string{"", "x.y", "6.beta"} {
+		if v, err := zapi.ParseSemVer(s); err == nil {
+			t.Errorf("%q: expected an error, got %s", s, v)
+		}
+	}
+
+	v := zapi.SemVer{6, 0, 12}
+	if !v.AtLeast(6, 0) || !v.AtLeast(5, 4) || v.AtLeast(6, 2) {
+		t.Errorf("Bad AtLeast for %s", v)
+	}
+	if v.Compare(zapi.SemVer{6, 0, 2}) != 1 || v.Compare(zapi.SemVer{6, 4, 0}) != -1 || v.Compare(v) != 0 {
+		t.Errorf("Bad Compare for %s", v)
+	}
+	if v.Packed() != 60012 || v.String() != "6.0.12" {
+		t.Errorf("Bad Packed or String: %d %s", v.Packed(), v)
+	}
+}
+
+// versionServer serves version, counts the calls to each method and connects APIs to it
+func versionServer(t *testing.T, version string) (count func(method string) int, newAPI func(c zapi.Config) (*zapi.API, error)) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	count = func(method string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[method]
+	}
+	srv := newRPCServer(t, version, func(r *http.Request, method string, p json.RawMessage, auth string) (interface{}, *zapi.Error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[method]++
+		if method == "user.login" {
+			if !strings.Contains(string(p), `"username"`) {
+				return nil, &zapi.Error{Code: -32602, Message: "Invalid params.", Data: string(p)}
+			}
+			return "session", nil
+		}
+		return []interface{}{}, nil
+	})
+	t.Cleanup(srv.Close)
+
+	// newRPCServer answers apiinfo.version itself, count it through a middleware
+	countVersion := func(next zapi.Doer) zapi.Doer {
+		return zapi.DoerFunc(func(ctx context.Context, req *zapi.RPCRequest) (*zapi.RPCResponse, error) {
+			if strings.EqualFold(req.Method, "apiinfo.version") {
+				mu.Lock()
+				calls["apiinfo.version"]++
+				mu.Unlock()
+			}
+			return next.Do(ctx, req)
+		})
+	}
+	newAPI = func(c zapi.Config) (*zapi.API, error) {
+		c.Url = srv.URL
+		c.Middleware = append(c.Middleware, countVersion)
+		return zapi.NewAPI(c)
+	}
+	return
+}
+
+func TestPresetVersion(t *testing.T) {
+	count, newAPI := versionServer(t, "6.0.0")
+
+	api, err := newAPI(zapi.Config{Version: 50400})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count("apiinfo.version"); n != 0 {
+		t.Errorf("Expected no detection, got %d calls", n)
+	}
+	if v, err := api.ServerVersion(); err != nil || v != (zapi.SemVer{5, 4, 0}) {
+		t.Errorf("Expected preset 5.4.0, got %s %v", v, err)
+	}
+}
+
+func TestLazyVersion(t *testing.T) {
+	count, newAPI := versionServer(t, "5.4.1")
+
+	api, err := newAPI(zapi.Config{LazyVersion: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := count("apiinfo.version"); n != 0 {
+		t.Fatalf("Expected no detection by NewAPI, got %d calls", n)
+	}
+
+	// login needs the version to name its parameters
+	if _, err = api.Login("Admin", "zabbix"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count("apiinfo.version"); n != 1 {
+		t.Errorf("Expected one detection, got %d calls", n)
+	}
+	v, err := api.ServerVersion()
+	if err != nil || v != (zapi.SemVer{5, 4, 1}) || !v.AtLeast(5, 4) {
+		t.Errorf("Expected 5.4.1, got %s %v", v, err)
+	}
+	if api.Config.Version != 50401 {
+		t.Errorf("Config.Version not set: %d", api.Config.Version)
+	}
+}
+
+func TestLazyVersionUnreachable(t *testing.T) {
+	api, err := zapi.NewAPI(zapi.Config{Url: "http://127.0.0.1:1/api_jsonrpc.php", LazyVersion: true})
+	if err != nil {
+		t.Fatalf("NewAPI failed: %s", err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err == nil {
+		t.Error("Expected an error")
+	}
+	if _, err = zapi.NewAPI(zapi.Config{Url: "http://127.0.0.1:1/api_jsonrpc.php"}); err == nil {
+		t.Error("Expected NewAPI to fail without LazyVersion")
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Package zabbixprom exports the instrumentation of github.com/tpretz/go-zabbix-api to Prometheus.
+
+It is a separate module so that the client itself does not depend on Prometheus.
+
+	m, err := zabbixprom.New(prometheus.DefaultRegisterer)
+	...
+	api, err := zabbix.NewAPI(zabbix.Config{Url: url, Metrics: m})
+*/
+package zabbixprom
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	zabbix "github.com/tpretz/go-zabbix-api"
+)
+
+// Metrics implements zabbix.Metrics with Prometheus collectors:
+//
+//	zabbix_api_calls_total{method, result}       calls by outcome: ok, error (API error) or transport_error
+//	zabbix_api_call_duration_seconds{method}      call latency histogram
+//	zabbix_api_errors_total{method, code}         API errors by JSON-RPC error code
+//	zabbix_api_http_responses_total{code}         HTTP responses by status code
+//	zabbix_api_calls_in_flight                    calls waiting for a response
+type Metrics struct {
+	calls     *prometheus.CounterVec
+	durations *prometheus.HistogramVec
+	errors    *prometheus.CounterVec
+	responses *prometheus.CounterVec
+	inFlight  prometheus.Gauge
+}
+
+// New creates the collectors and registers them with reg.
+func New(reg prometheus.Registerer) (m *Metrics, err error) {
+	m = &Metrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "zabbix_api_calls_total",
+			Help: "Zabbix API calls by method and result.",
+		}, []string{"method", "result"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "zabbix_api_call_duration_seconds",
+			Help:    "Zabbix API call latency by method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "zabbix_api_errors_total",
+			Help: "Zabbix API errors by method and JSON-RPC error code.",
+		}, []string{"method", "code"}),
+		responses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "zabbix_api_http_responses_total",
+			Help: "HTTP responses of the Zabbix frontend by status code.",
+		}, []string{"code"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "zabbix_api_calls_in_flight",
+			Help: "Zabbix API calls waiting for a response.",
+		}),
+	}
+	for _, c := range []prometheus.Collector{m.calls, m.durations, m.errors, m.responses, m.inFlight} {
+		if err = reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+// CallStarted implements zabbix.Metrics
+func (m *Metrics) CallStarted(method string) {
+	m.inFlight.Inc()
+}
+
+// CallDone implements zabbix.Metrics
+func (m *Metrics) CallDone(method string, elapsed time.Duration, err error) {
+	m.inFlight.Dec()
+	m.durations.WithLabelValues(method).Observe(elapsed.Seconds())
+
+	var e *zabbix.Error
+	switch {
+	case err == nil:
+		m.calls.WithLabelValues(method, "ok").Inc()
+	case errors.As(err, &e):
+		m.calls.WithLabelValues(method, "error").Inc()
+		m.errors.WithLabelValues(method, strconv.Itoa(e.Code)).Inc()
+	default:
+		m.calls.WithLabelValues(method, "transport_error").Inc()
+	}
+}
+
+// HTTPResponse implements zabbix.Metrics
+func (m *Metrics) HTTPResponse(status int) {
+	m.responses.WithLabelValues(strconv.Itoa(status)).Inc()
+}
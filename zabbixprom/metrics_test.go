@@ -0,0 +1,76 @@
+package zabbixprom_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixprom"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := zabbixprom.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := zabbixtest.NewServer(zabbixtest.Config{})
+	defer srv.Close()
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Metrics: m})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.CallWithError("host.nope", zapi.Params{}); err == nil {
+		t.Fatal("Expected error")
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	if _, err = zapi.NewAPI(zapi.Config{Url: down.URL, Metrics: m}); err == nil {
+		t.Fatal("Expected HTTP error")
+	}
+
+	expected := `
+# HELP zabbix_api_calls_total Zabbix API calls by method and result.
+# TYPE zabbix_api_calls_total counter
+zabbix_api_calls_total{method="APIInfo.version",result="ok"} 1
+zabbix_api_calls_total{method="APIInfo.version",result="transport_error"} 1
+zabbix_api_calls_total{method="host.get",result="ok"} 2
+zabbix_api_calls_total{method="host.nope",result="error"} 1
+zabbix_api_calls_total{method="user.login",result="ok"} 1
+# HELP zabbix_api_errors_total Zabbix API errors by method and JSON-RPC error code.
+# TYPE zabbix_api_errors_total counter
+zabbix_api_errors_total{code="-32601",method="host.nope"} 1
+# HELP zabbix_api_http_responses_total HTTP responses of the Zabbix frontend by status code.
+# TYPE zabbix_api_http_responses_total counter
+zabbix_api_http_responses_total{code="200"} 5
+zabbix_api_http_responses_total{code="502"} 1
+# HELP zabbix_api_calls_in_flight Zabbix API calls waiting for a response.
+# TYPE zabbix_api_calls_in_flight gauge
+zabbix_api_calls_in_flight 0
+`
+	names := []string{"zabbix_api_calls_total", "zabbix_api_errors_total", "zabbix_api_http_responses_total", "zabbix_api_calls_in_flight"}
+	if err = testutil.GatherAndCompare(reg, strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(reg, "zabbix_api_call_duration_seconds"); n != 4 {
+		t.Errorf("Expected 4 histograms, got %d", n)
+	}
+}
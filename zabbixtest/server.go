@@ -0,0 +1,333 @@
+/*
+Package zabbixtest provides an in-process fake of the Zabbix JSON-RPC API,
+so code built on top of github.com/tpretz/go-zabbix-api can be tested without a Zabbix server.
+
+	srv := zabbixtest.NewServer(zabbixtest.Config{Version: "6.0.0"})
+	defer srv.Close()
+
+	api, err := zabbix.NewAPI(zabbix.Config{Url: srv.URL})
+	...
+	api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword)
+
+The fake implements apiinfo.version, user.login, user.logout and in-memory get, create, update
+and delete for hosts, host groups, templates, items, triggers, user macros, graphs, discovery rules,
+their prototypes, applications, proxies and API tokens.
+Only the most common get parameters are understood: ids, filter, search, output, select*,
+sortfield, sortorder and limit. Behaviour that depends on the version, such as the user.login
+parameter name, the Authorization header or methods removed or added over time, follows Config.Version.
+*/
+package zabbixtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultVersion is the version reported when Config.Version is empty
+	DefaultVersion = "5.0.0"
+	// DefaultUser and DefaultPassword are accepted by user.login when Config.Users is nil
+	DefaultUser     = "Admin"
+	DefaultPassword = "zabbix"
+)
+
+// Config of a fake server
+type Config struct {
+	// Version reported by apiinfo.version, DefaultVersion if empty
+	Version string
+	// Users maps user names to passwords, DefaultUser only if nil
+	Users map[string]string
+}
+
+// Server is a fake Zabbix frontend backed by memory.
+// It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	version int
+	raw     string
+	users   map[string]string
+
+	mu       sync.Mutex
+	sessions map[string]string
+	secrets  map[string]string
+	tables   map[string]*table
+	lastID   int
+}
+
+// NewServer starts a fake server, callers should Close it when done.
+func NewServer(c Config) *Server {
+	if c.Version == "" {
+		c.Version = DefaultVersion
+	}
+	if c.Users == nil {
+		c.Users = map[string]string{DefaultUser: DefaultPassword}
+	}
+
+	s := &Server{
+		version:  packVersion(c.Version),
+		raw:      c.Version,
+		users:    c.Users,
+		sessions: map[string]string{},
+		secrets:  map[string]string{},
+		tables:   map[string]*table{},
+	}
+	for _, t := range newTables() {
+		if s.version >= t.since && (t.until == 0 || s.version < t.until) {
+			s.tables[t.name] = t
+		}
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// packVersion packs "major.minor.patch" like the client does, 5.4.2 is 50402
+func packVersion(v string) (packed int) {
+	for i, part := range strings.SplitN(v, ".", 3) {
+		n, _ := strconv.Atoi(part)
+		switch i {
+		case 0:
+			packed += n * 10000
+		case 1:
+			packed += n * 100
+		case 2:
+			packed += n
+		}
+	}
+	return
+}
+
+// ExpireSessions forgets all sessions created by user.login,
+// the next calls using them fail like with an expired session.
+func (s *Server) ExpireSessions() {
+	s.mu.Lock()
+	s.sessions = map[string]string{}
+	s.mu.Unlock()
+}
+
+// Add stores objects of the given API object type (such as "host" or "proxy") without validation
+// and returns their new ids. It panics on unknown types, it is meant to seed tests.
+func (s *Server) Add(kind string, objects ...interface{}) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[kind]
+	if !ok {
+		panic(fmt.Sprintf("zabbixtest: unknown object %q", kind))
+	}
+	b, err := json.Marshal(objects)
+	if err != nil {
+		panic(err)
+	}
+	var objs []object
+	if err := json.Unmarshal(b, &objs); err != nil {
+		panic(err)
+	}
+	ids := make([]string, len(objs))
+	for i, o := range objs {
+		ids[i] = s.insert(t, o)
+	}
+	return ids
+}
+
+func (s *Server) newID() string {
+	s.lastID++
+	return strconv.Itoa(s.lastID)
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+type request struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	Auth    string          `json:"auth"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type response struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data"`
+}
+
+func invalidParams(format string, v ...interface{}) *rpcError {
+	return &rpcError{-32602, "Invalid params.", fmt.Sprintf(format, v...)}
+}
+
+func applicationError(format string, v ...interface{}) *rpcError {
+	return &rpcError{-32500, "Application error.", fmt.Sprintf(format, v...)}
+}
+
+var errNoObject = applicationError("No permissions to referred object or it does not exist!")
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeJSON(w, response{Jsonrpc: "2.0", Error: &rpcError{-32700, "Parse error.", "Invalid JSON. An error occurred on the server while parsing the JSON text."}})
+		return
+	}
+
+	bearer := ""
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") && s.version >= 60400 {
+		bearer = strings.TrimPrefix(h, "Bearer ")
+	}
+
+	// batch
+	if trimmed := strings.TrimSpace(string(raw)); strings.HasPrefix(trimmed, "[") {
+		var reqs []request
+		if err := json.Unmarshal(raw, &reqs); err != nil || len(reqs) == 0 {
+			writeJSON(w, response{Jsonrpc: "2.0", Error: &rpcError{-32600, "Invalid Request.", "Invalid batch request."}})
+			return
+		}
+		res := make([]response, len(reqs))
+		for i, req := range reqs {
+			res[i] = s.handle(req, bearer)
+		}
+		writeJSON(w, res)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(raw, &req); err != nil {
+		writeJSON(w, response{Jsonrpc: "2.0", Error: &rpcError{-32600, "Invalid Request.", err.Error()}})
+		return
+	}
+	writeJSON(w, s.handle(req, bearer))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func (s *Server) handle(req request, bearer string) response {
+	res := response{Jsonrpc: "2.0", ID: req.ID}
+	result, err := s.dispatch(req, bearer)
+	if err != nil {
+		res.Error = err
+	} else {
+		res.Result = result
+	}
+	return res
+}
+
+func (s *Server) dispatch(req request, bearer string) (interface{}, *rpcError) {
+	method := strings.ToLower(req.Method)
+	switch method {
+	case "apiinfo.version":
+		if req.Auth != "" && s.version >= 60400 {
+			return nil, invalidParams(`The "apiinfo.version" method must be called without the "auth" parameter.`)
+		}
+		return s.raw, nil
+	case "user.login":
+		return s.login(req.Params)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth := req.Auth
+	if auth == "" {
+		auth = bearer
+	}
+	if auth == "" {
+		return nil, invalidParams("Not authorised.")
+	}
+	_, session := s.sessions[auth]
+	_, token := s.secrets[auth]
+	if !session && !token {
+		return nil, invalidParams("Session terminated, re-login, please.")
+	}
+
+	parts := strings.SplitN(method, ".", 2)
+	if len(parts) != 2 {
+		return nil, invalidParams("Incorrect API %q.", req.Method)
+	}
+	if method == "user.logout" {
+		delete(s.sessions, auth)
+		return true, nil
+	}
+	if method == "token.generate" {
+		if t, ok := s.tables["token"]; ok {
+			return s.generateTokens(t, req.Params)
+		}
+	}
+
+	t, ok := s.tables[parts[0]]
+	if !ok {
+		return nil, invalidParams("Incorrect API %q.", parts[0])
+	}
+	switch parts[1] {
+	case "get":
+		return s.get(t, req.Params)
+	case "create":
+		return s.create(t, req.Params)
+	case "update":
+		return s.update(t, req.Params)
+	case "delete":
+		return s.delete(t, req.Params)
+	}
+	return nil, &rpcError{-32601, "Method not found.", fmt.Sprintf("Incorrect method %q.", req.Method)}
+}
+
+func (s *Server) login(params json.RawMessage) (interface{}, *rpcError) {
+	var p map[string]string
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, invalidParams(`Invalid parameter "/": an array is expected.`)
+	}
+
+	userField, oldField := "user", "username"
+	if s.version >= 50400 {
+		userField, oldField = "username", "user"
+	}
+	if _, ok := p[oldField]; ok {
+		return nil, invalidParams(`Invalid parameter "/": unexpected parameter %q.`, oldField)
+	}
+
+	user := p[userField]
+	if password, ok := s.users[user]; !ok || password != p["password"] {
+		return nil, applicationError("Incorrect user name or password or account is temporarily blocked.")
+	}
+
+	sid := newSecret()[:32]
+	s.mu.Lock()
+	s.sessions[sid] = user
+	s.mu.Unlock()
+	return sid, nil
+}
+
+func (s *Server) generateTokens(t *table, params json.RawMessage) (interface{}, *rpcError) {
+	var ids []string
+	if err := json.Unmarshal(params, &ids); err != nil {
+		return nil, invalidParams(`Invalid parameter "/": an array is expected.`)
+	}
+	res := []map[string]string{}
+	for _, id := range ids {
+		if _, ok := t.objects[id]; !ok {
+			return nil, errNoObject
+		}
+		secret := newSecret()
+		s.secrets[secret] = id
+		res = append(res, map[string]string{"tokenid": id, "token": secret})
+	}
+	return res, nil
+}
@@ -0,0 +1,121 @@
+package zabbixtest_test
+
+import (
+	"errors"
+	"testing"
+
+	zapi "github.com/tpretz/go-zabbix-api"
+	"github.com/tpretz/go-zabbix-api/zabbixtest"
+)
+
+func newAPI(t *testing.T, version string) (*zabbixtest.Server, *zapi.API) {
+	srv := zabbixtest.NewServer(zabbixtest.Config{Version: version})
+	api, err := zapi.NewAPI(zapi.Config{Url: srv.URL, Relogin: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = api.Login(zabbixtest.DefaultUser, zabbixtest.DefaultPassword); err != nil {
+		t.Fatal(err)
+	}
+	return srv, api
+}
+
+func TestVersions(t *testing.T) {
+	for _, v := range []string{"4.0.0", "5.0.0", "5.4.0", "6.4.0", "7.0.0"} {
+		srv, api := newAPI(t, v)
+
+		groups := zapi.HostGroups{{Name: "group"}}
+		if err := api.HostGroupsCreate(groups); err != nil {
+			t.Fatalf("%s: %s", v, err)
+		}
+		hosts := zapi.Hosts{{Host: "host", GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}}}}
+		if err := api.HostsCreate(hosts); err != nil {
+			t.Fatalf("%s: %s", v, err)
+		}
+
+		_, err := api.ApplicationsGet(zapi.Params{})
+		if hasApps := err == nil; hasApps != (api.Config.Version < 50400) {
+			t.Errorf("%s: unexpected application.get result: %v", v, err)
+		}
+		_, err = api.TokensGet(zapi.Params{})
+		if hasTokens := err == nil; hasTokens != (api.Config.Version >= 50400) {
+			t.Errorf("%s: unexpected token.get result: %v", v, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestCRUD(t *testing.T) {
+	srv, api := newAPI(t, "")
+	defer srv.Close()
+
+	groups := zapi.HostGroups{{Name: "linux"}}
+	if err := api.HostGroupsCreate(groups); err != nil {
+		t.Fatal(err)
+	}
+	err := api.HostGroupsCreate(zapi.HostGroups{{Name: "linux"}})
+	if !errors.Is(err, zapi.ErrAlreadyExists) {
+		t.Errorf("Expected already exists, got %v", err)
+	}
+
+	hosts := zapi.Hosts{
+		{Host: "a", GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}}},
+		{Host: "b", GroupIds: zapi.HostGroupIDs{{GroupID: groups[0].GroupID}}},
+	}
+	if err = api.HostsCreate(hosts); err != nil {
+		t.Fatal(err)
+	}
+	items := zapi.Items{{HostID: hosts[0].HostID, Key: "k", Name: "k", Type: zapi.ZabbixTrapper}}
+	if err = api.ItemsCreate(items); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := api.HostsGet(zapi.Params{"groupids": groups[0].GroupID, "selectGroups": "extend", "sortfield": "host", "sortorder": "DESC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 2 || res[0].Host != "b" || len(res[0].GroupIds) != 1 {
+		t.Errorf("Bad hosts: %#v", res)
+	}
+
+	if err = api.HostsDelete(hosts[:1]); err != nil {
+		t.Fatal(err)
+	}
+	// items go away with their host
+	if _, err = api.ItemGetByID(items[0].ItemID); !errors.Is(err, zapi.ErrNotFound) {
+		t.Errorf("Expected item to be deleted, got %v", err)
+	}
+	if err = api.HostsDeleteByIds([]string{hosts[0].HostID}); !errors.Is(err, zapi.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
+
+func TestExpireSessions(t *testing.T) {
+	srv, api := newAPI(t, "")
+	defer srv.Close()
+
+	srv.ExpireSessions()
+	if _, err := api.HostsGet(zapi.Params{}); err != nil {
+		t.Fatal(err)
+	}
+
+	api.Config.Relogin = false
+	srv.ExpireSessions()
+	if _, err := api.HostsGet(zapi.Params{}); !errors.Is(err, zapi.ErrSessionExpired) {
+		t.Errorf("Expected session expired, got %v", err)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	srv, api := newAPI(t, "")
+	defer srv.Close()
+
+	ids := srv.Add("proxy", zapi.Proxy{Host: "proxy1"}, zapi.Proxy{Host: "proxy2"})
+	proxies, err := api.ProxiesGet(zapi.Params{"proxyids": ids[1]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proxies) != 1 || proxies[0].Host != "proxy2" {
+		t.Errorf("Bad proxies: %#v", proxies)
+	}
+}
@@ -0,0 +1,548 @@
+package zabbixtest
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type object map[string]interface{}
+
+// selection is a field only returned when its select* parameter is given
+type selection struct {
+	// field is where the value is stored
+	field string
+	// name is the returned field, field if empty
+	name string
+	// value computes the returned value, the stored field is returned if nil
+	value func(s *Server, o object) interface{}
+}
+
+// table stores the objects of one API object type
+type table struct {
+	name      string // API object, such as "host"
+	idField   string // "hostid"
+	idsParam  string // get parameter filtering on ids, "hostids"
+	resultKey string // key listing ids in create and update results, "hostids"
+	deleteKey string // key listing ids in delete results, resultKey if empty
+
+	// unique field and the error data used when it is duplicated,
+	// uniqueScope restricts uniqueness to objects sharing that field
+	unique      string
+	uniqueScope string
+	uniqueMsg   string
+
+	defaults   object
+	selections map[string]selection
+	// relations map get parameters to the ids an object relates to
+	relations map[string]func(s *Server, o object) []string
+
+	// available from since up to, but excluding, until (0 means no limit)
+	since, until int
+
+	objects map[string]object
+}
+
+func newTables() []*table {
+	hostIDs := func(s *Server, o object) []string { return []string{str(o["hostid"])} }
+	return []*table{
+		{
+			name: "host", idField: "hostid", idsParam: "hostids", resultKey: "hostids",
+			unique: "host", uniqueMsg: "Host with the same name %q already exists.",
+			defaults: object{"status": "0", "available": "0", "error": "", "proxy_hostid": "0", "inventory_mode": "-1"},
+			selections: map[string]selection{
+				"selectGroups":          {field: "groups"},
+				"selectInterfaces":      {field: "interfaces"},
+				"selectParentTemplates": {field: "templates", name: "parentTemplates"},
+				"selectMacros":          {field: "macros"},
+				"selectTags":            {field: "tags"},
+				"selectInventory":       {field: "inventory"},
+			},
+			relations: map[string]func(*Server, object) []string{
+				"groupids":    listField("groups", "groupid"),
+				"templateids": listField("templates", "templateid"),
+				"proxyids":    func(s *Server, o object) []string { return []string{str(o["proxy_hostid"])} },
+			},
+		},
+		{
+			name: "hostgroup", idField: "groupid", idsParam: "groupids", resultKey: "groupids",
+			unique: "name", uniqueMsg: "Host group %q already exists.",
+			defaults: object{"internal": "0", "flags": "0"},
+			relations: map[string]func(*Server, object) []string{
+				"hostids": func(s *Server, o object) (ids []string) {
+					for id, h := range s.tables["host"].objects {
+						for _, gid := range listField("groups", "groupid")(s, h) {
+							if gid == o["groupid"] {
+								ids = append(ids, id)
+							}
+						}
+					}
+					return
+				},
+			},
+		},
+		{
+			name: "template", idField: "templateid", idsParam: "templateids", resultKey: "templateids",
+			unique: "host", uniqueMsg: "Template with the same name %q already exists.",
+			selections: map[string]selection{
+				"selectGroups":          {field: "groups"},
+				"selectMacros":          {field: "macros"},
+				"selectParentTemplates": {field: "templates", name: "parentTemplates"},
+				"selectHosts":           {field: "hosts"},
+				"selectTags":            {field: "tags"},
+			},
+			relations: map[string]func(*Server, object) []string{
+				"groupids": listField("groups", "groupid"),
+			},
+		},
+		itemTable("item", "itemids"),
+		itemTable("itemprototype", "prototypeids"),
+		triggerTable("trigger"),
+		triggerTable("triggerprototype"),
+		graphTable("graph"),
+		graphTable("graphprototype"),
+		{
+			name: "discoveryrule", idField: "itemid", idsParam: "itemids", resultKey: "itemids", deleteKey: "ruleids",
+			unique: "key_", uniqueScope: "hostid", uniqueMsg: "Discovery rule with key %q already exists.",
+			defaults: object{"status": "0", "error": ""},
+			selections: map[string]selection{
+				"selectFilter":        {field: "filter"},
+				"selectLLDMacroPaths": {field: "lld_macro_paths"},
+				"selectPreprocessing": {field: "preprocessing"},
+			},
+			relations: map[string]func(*Server, object) []string{"hostids": hostIDs},
+		},
+		{
+			name: "usermacro", idField: "hostmacroid", idsParam: "hostmacroids", resultKey: "hostmacroids",
+			unique: "macro", uniqueScope: "hostid", uniqueMsg: "Macro %q already exists.",
+			relations: map[string]func(*Server, object) []string{"hostids": hostIDs},
+		},
+		{
+			name: "application", idField: "applicationid", idsParam: "applicationids", resultKey: "applicationids",
+			unique: "name", uniqueScope: "hostid", uniqueMsg: "Application %q already exists.",
+			relations: map[string]func(*Server, object) []string{"hostids": hostIDs},
+			until:     50400,
+		},
+		{
+			name: "proxy", idField: "proxyid", idsParam: "proxyids", resultKey: "proxyids",
+			unique: "host", uniqueMsg: "Proxy %q already exists.",
+		},
+		{
+			name: "token", idField: "tokenid", idsParam: "tokenids", resultKey: "tokenids",
+			unique: "name", uniqueScope: "userid", uniqueMsg: "API token %q already exists.",
+			defaults: object{"status": "0", "expires_at": "0", "lastaccess": "0"},
+			since:    50400,
+		},
+	}
+}
+
+func itemTable(name, deleteKey string) *table {
+	return &table{
+		name: name, idField: "itemid", idsParam: "itemids", resultKey: "itemids", deleteKey: deleteKey,
+		unique: "key_", uniqueScope: "hostid", uniqueMsg: "Item with key %q already exists.",
+		defaults: object{"status": "0", "error": "", "templateid": "0"},
+		selections: map[string]selection{
+			"selectApplications": {field: "applications", value: func(s *Server, o object) interface{} {
+				apps := []object{}
+				for _, id := range list(o["applications"]) {
+					apps = append(apps, object{"applicationid": str(id)})
+				}
+				return apps
+			}},
+			"selectHosts": {field: "hosts", value: func(s *Server, o object) interface{} {
+				return []object{{"hostid": str(o["hostid"])}}
+			}},
+			"selectPreprocessing": {field: "preprocessing"},
+			"selectTags":          {field: "tags"},
+			"selectDiscoveryRule": {field: "discoveryRule"},
+		},
+		relations: map[string]func(*Server, object) []string{
+			"hostids": func(s *Server, o object) []string { return []string{str(o["hostid"])} },
+			"applicationids": func(s *Server, o object) (ids []string) {
+				for _, id := range list(o["applications"]) {
+					ids = append(ids, str(id))
+				}
+				return
+			},
+			"discoveryids": func(s *Server, o object) []string { return []string{str(o["ruleid"])} },
+		},
+	}
+}
+
+// expressionHosts matches host names in old {host:key.func()} and new func(/host/key) trigger expressions
+var expressionHosts = regexp.MustCompile(`\{([^:{}]+):|\(/([^/]+)/`)
+
+func triggerHostIDs(s *Server, o object) (ids []string) {
+	for _, m := range expressionHosts.FindAllStringSubmatch(str(o["expression"]), -1) {
+		name := m[1] + m[2]
+		for _, kind := range []string{"host", "template"} {
+			for id, h := range s.tables[kind].objects {
+				if h["host"] == name {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return
+}
+
+func triggerTable(name string) *table {
+	return &table{
+		name: name, idField: "triggerid", idsParam: "triggerids", resultKey: "triggerids",
+		defaults: object{"status": "0", "value": "0", "priority": "0", "state": "0", "error": ""},
+		selections: map[string]selection{
+			"selectHosts": {field: "hosts", value: func(s *Server, o object) interface{} {
+				hosts := []object{}
+				for _, id := range triggerHostIDs(s, o) {
+					hosts = append(hosts, object{"hostid": id})
+				}
+				return hosts
+			}},
+			"selectTags":         {field: "tags"},
+			"selectDependencies": {field: "dependencies"},
+			"selectFunctions":    {field: "functions"},
+			"selectItems":        {field: "items"},
+		},
+		relations: map[string]func(*Server, object) []string{
+			"hostids": triggerHostIDs,
+		},
+	}
+}
+
+func graphTable(name string) *table {
+	return &table{
+		name: name, idField: "graphid", idsParam: "graphids", resultKey: "graphids",
+		selections: map[string]selection{
+			"selectGraphItems": {field: "gitems"},
+		},
+		relations: map[string]func(*Server, object) []string{
+			"itemids": listField("gitems", "itemid"),
+		},
+	}
+}
+
+// listField extracts field from each object of the list stored under key
+func listField(key, field string) func(*Server, object) []string {
+	return func(s *Server, o object) (ids []string) {
+		for _, v := range list(o[key]) {
+			if m, ok := v.(map[string]interface{}); ok {
+				ids = append(ids, str(m[field]))
+			}
+		}
+		return
+	}
+}
+
+func list(v interface{}) []interface{} {
+	switch l := v.(type) {
+	case []interface{}:
+		return l
+	case nil:
+		return nil
+	}
+	return []interface{}{v}
+}
+
+func str(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// decodeObjects accepts a single object or an array of objects
+func decodeObjects(params json.RawMessage) ([]object, *rpcError) {
+	var objs []object
+	if err := json.Unmarshal(params, &objs); err == nil {
+		return objs, nil
+	}
+	var o object
+	if err := json.Unmarshal(params, &o); err != nil {
+		return nil, invalidParams(`Invalid parameter "/": an array or object is expected.`)
+	}
+	return []object{o}, nil
+}
+
+func (s *Server) insert(t *table, o object) string {
+	if t.objects == nil {
+		t.objects = map[string]object{}
+	}
+	id := s.newID()
+	stored := object{}
+	for k, v := range t.defaults {
+		stored[k] = v
+	}
+	for k, v := range o {
+		stored[k] = v
+	}
+	stored[t.idField] = id
+	t.objects[id] = stored
+	return id
+}
+
+// checkUnique fails if another object of t already uses the unique value of o
+func (t *table) checkUnique(o object, self string) *rpcError {
+	if t.unique == "" {
+		return nil
+	}
+	v, ok := o[t.unique]
+	if !ok {
+		return nil
+	}
+	for id, other := range t.objects {
+		if id == self || str(other[t.unique]) != str(v) {
+			continue
+		}
+		if t.uniqueScope != "" && str(other[t.uniqueScope]) != str(o[t.uniqueScope]) {
+			continue
+		}
+		return invalidParams(t.uniqueMsg, str(v))
+	}
+	return nil
+}
+
+func (s *Server) create(t *table, params json.RawMessage) (interface{}, *rpcError) {
+	objs, err := decodeObjects(params)
+	if err != nil {
+		return nil, err
+	}
+	for i, o := range objs {
+		if err := t.checkUnique(o, ""); err != nil {
+			return nil, err
+		}
+		// duplicates within the same call
+		for _, prev := range objs[:i] {
+			if t.unique != "" && str(prev[t.unique]) == str(o[t.unique]) && str(prev[t.uniqueScope]) == str(o[t.uniqueScope]) {
+				return nil, invalidParams(t.uniqueMsg, str(o[t.unique]))
+			}
+		}
+	}
+
+	ids := []string{}
+	for _, o := range objs {
+		delete(o, t.idField)
+		ids = append(ids, s.insert(t, o))
+	}
+	return map[string][]string{t.resultKey: ids}, nil
+}
+
+func (s *Server) update(t *table, params json.RawMessage) (interface{}, *rpcError) {
+	objs, err := decodeObjects(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objs {
+		id := str(o[t.idField])
+		if id == "" {
+			return nil, invalidParams(`Invalid parameter "/1": the parameter %q is missing.`, t.idField)
+		}
+		stored, ok := t.objects[id]
+		if !ok {
+			return nil, errNoObject
+		}
+		merged := object{}
+		for k, v := range stored {
+			merged[k] = v
+		}
+		for k, v := range o {
+			merged[k] = v
+		}
+		if err := t.checkUnique(merged, id); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := []string{}
+	for _, o := range objs {
+		id := str(o[t.idField])
+		for k, v := range o {
+			t.objects[id][k] = v
+		}
+		ids = append(ids, id)
+	}
+	return map[string][]string{t.resultKey: ids}, nil
+}
+
+func (s *Server) delete(t *table, params json.RawMessage) (interface{}, *rpcError) {
+	var ids []string
+	if err := json.Unmarshal(params, &ids); err != nil {
+		return nil, invalidParams(`Invalid parameter "/": an array is expected.`)
+	}
+	for _, id := range ids {
+		if _, ok := t.objects[id]; !ok {
+			return nil, errNoObject
+		}
+	}
+	for _, id := range ids {
+		delete(t.objects, id)
+		if t.name == "host" || t.name == "template" {
+			s.cascade(id)
+		}
+	}
+
+	key := t.deleteKey
+	if key == "" {
+		key = t.resultKey
+	}
+	return map[string][]string{key: ids}, nil
+}
+
+// cascade removes objects that belonged to a deleted host or template
+func (s *Server) cascade(hostID string) {
+	for _, t := range s.tables {
+		for id, o := range t.objects {
+			if t.name != "host" && t.name != "template" && o["hostid"] == hostID {
+				delete(t.objects, id)
+			}
+		}
+	}
+}
+
+func (s *Server) get(t *table, params json.RawMessage) (interface{}, *rpcError) {
+	p := map[string]interface{}{}
+	if len(params) != 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, invalidParams(`Invalid parameter "/": an array is expected.`)
+		}
+	}
+
+	var matched []object
+	for _, o := range t.objects {
+		if s.matches(t, o, p) {
+			matched = append(matched, o)
+		}
+	}
+	sortObjects(matched, p, t.idField)
+
+	if limit, ok := p["limit"]; ok {
+		if n, err := strconv.Atoi(str(limit)); err == nil && n >= 0 && n < len(matched) {
+			matched = matched[:n]
+		}
+	}
+
+	res := []object{}
+	for _, o := range matched {
+		res = append(res, s.output(t, o, p))
+	}
+	return res, nil
+}
+
+func (s *Server) matches(t *table, o object, p map[string]interface{}) bool {
+	for param, v := range p {
+		var have []string
+		if param == t.idsParam {
+			have = []string{str(o[t.idField])}
+		} else if rel, ok := t.relations[param]; ok {
+			have = rel(s, o)
+		} else {
+			continue
+		}
+		if !intersects(have, list(v)) {
+			return false
+		}
+	}
+
+	if filter, ok := p["filter"].(map[string]interface{}); ok {
+		for field, v := range filter {
+			if !intersects([]string{str(o[field])}, list(v)) {
+				return false
+			}
+		}
+	}
+	if search, ok := p["search"].(map[string]interface{}); ok {
+		for field, v := range search {
+			if !strings.Contains(strings.ToLower(str(o[field])), strings.ToLower(str(v))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func intersects(have []string, want []interface{}) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h == str(w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sortObjects orders by sortfield, numerically when both values are numbers, by id otherwise
+func sortObjects(objs []object, p map[string]interface{}, idField string) {
+	fields := []string{}
+	for _, f := range list(p["sortfield"]) {
+		fields = append(fields, str(f))
+	}
+	fields = append(fields, idField)
+	desc := strings.EqualFold(str(p["sortorder"]), "DESC")
+
+	sort.SliceStable(objs, func(i, j int) bool {
+		for _, f := range fields {
+			a, b := str(objs[i][f]), str(objs[j][f])
+			if a == b {
+				continue
+			}
+			less := a < b
+			na, errA := strconv.ParseFloat(a, 64)
+			nb, errB := strconv.ParseFloat(b, 64)
+			if errA == nil && errB == nil {
+				less = na < nb
+			}
+			return less != desc
+		}
+		return false
+	})
+}
+
+func (s *Server) output(t *table, o object, p map[string]interface{}) object {
+	hidden := map[string]bool{}
+	for _, sel := range t.selections {
+		hidden[sel.field] = true
+	}
+
+	res := object{}
+	switch out := p["output"].(type) {
+	case []interface{}:
+		for _, f := range out {
+			if v, ok := o[str(f)]; ok && !hidden[str(f)] {
+				res[str(f)] = v
+			}
+		}
+		res[t.idField] = o[t.idField]
+	default:
+		for k, v := range o {
+			if !hidden[k] {
+				res[k] = v
+			}
+		}
+	}
+
+	for param, sel := range t.selections {
+		if _, ok := p[param]; !ok {
+			continue
+		}
+		name, value := sel.name, o[sel.field]
+		if name == "" {
+			name = sel.field
+		}
+		if sel.value != nil {
+			value = sel.value(s, o)
+		}
+		if value == nil {
+			value = []interface{}{}
+		}
+		res[name] = value
+	}
+	return res
+}
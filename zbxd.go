@@ -0,0 +1,239 @@
+package zabbix
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// ZBXD header flags
+// https://www.zabbix.com/documentation/current/en/manual/appendix/protocols/header_datalen
+const (
+	zbxdProtocol   byte = 0x01
+	zbxdCompressed byte = 0x02
+	zbxdLarge      byte = 0x04
+)
+
+// zbxdMaxSize caps the size of received packets, compressed or not
+const zbxdMaxSize = 128 << 20
+
+// writeFrame sends data with the ZBXD header, compressed with zlib if compress is set
+func writeFrame(w io.Writer, data []byte, compress bool) error {
+	flags, size := zbxdProtocol, len(data)
+	if compress {
+		var buf bytes.Buffer
+		z := zlib.NewWriter(&buf)
+		if _, err := z.Write(data); err != nil {
+			return err
+		}
+		if err := z.Close(); err != nil {
+			return err
+		}
+		flags |= zbxdCompressed
+		data = buf.Bytes()
+	} else {
+		size = 0
+	}
+
+	header := make([]byte, 13, 13+len(data))
+	copy(header, "ZBXD")
+	header[4] = flags
+	binary.LittleEndian.PutUint32(header[5:], uint32(len(data)))
+	// reserved, the uncompressed size of compressed data
+	binary.LittleEndian.PutUint32(header[9:], uint32(size))
+	_, err := w.Write(append(header, data...))
+	return err
+}
+
+// readFrame reads a packet with the ZBXD header and returns its uncompressed data
+func readFrame(r io.Reader) (data []byte, err error) {
+	header := make([]byte, 5)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	if string(header[:4]) != "ZBXD" {
+		return nil, fmt.Errorf("bad ZBXD header %q", header)
+	}
+	flags := header[4]
+
+	var size, uncompressed uint64
+	if flags&zbxdLarge != 0 {
+		sizes := make([]byte, 16)
+		if _, err = io.ReadFull(r, sizes); err != nil {
+			return
+		}
+		size, uncompressed = binary.LittleEndian.Uint64(sizes), binary.LittleEndian.Uint64(sizes[8:])
+	} else {
+		sizes := make([]byte, 8)
+		if _, err = io.ReadFull(r, sizes); err != nil {
+			return
+		}
+		size, uncompressed = uint64(binary.LittleEndian.Uint32(sizes)), uint64(binary.LittleEndian.Uint32(sizes[4:]))
+	}
+	if size > zbxdMaxSize || uncompressed > zbxdMaxSize {
+		return nil, fmt.Errorf("ZBXD packet of %d bytes is too large", size)
+	}
+
+	data = make([]byte, size)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	if flags&zbxdCompressed == 0 {
+		return
+	}
+
+	z, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	data = make([]byte, uncompressed)
+	if _, err = io.ReadFull(z, data); err != nil {
+		return nil, fmt.Errorf("bad compressed ZBXD packet: %w", err)
+	}
+	return
+}
+
+// TLSConnect is how connections to Zabbix servers, proxies and agents are encrypted,
+// like the --tls-connect option of zabbix_sender and zabbix_get
+type TLSConnect string
+
+const (
+	// TLSUnencrypted connects without encryption (default)
+	TLSUnencrypted TLSConnect = "unencrypted"
+	// TLSPSK connects with a pre-shared key
+	TLSPSK TLSConnect = "psk"
+	// TLSCert connects with certificates
+	TLSCert TLSConnect = "cert"
+)
+
+// ErrPSKUnsupported is returned when TLSPSK is requested without TLSOptions.PSKDial
+var ErrPSKUnsupported = errors.New("zabbix: TLS PSK needs PSKDial, crypto/tls does not support pre-shared keys")
+
+// TLSOptions configures the encryption of connections to Zabbix servers, proxies and agents.
+type TLSOptions struct {
+	TLSConnect TLSConnect
+	// TLSConfig holds the certificates of TLSCert, ServerName defaults to the host of the address
+	TLSConfig *tls.Config
+	// TLSPSKIdentity is the identity of the pre-shared key
+	TLSPSKIdentity string
+	// TLSPSK is the hex encoded pre-shared key, read from TLSPSKFile if empty
+	TLSPSK     string
+	TLSPSKFile string
+	// PSKDial opens TLSPSK connections. It must be provided with a TLS implementation supporting
+	// pre-shared keys, such as the TLS_PSK cipher suites of OpenSSL.
+	PSKDial func(ctx context.Context, addr, identity string, psk []byte) (net.Conn, error)
+}
+
+// psk checks the TLSPSK options and returns the decoded key
+func (o *TLSOptions) psk() (key []byte, err error) {
+	if o.TLSPSKIdentity == "" {
+		return nil, errors.New("zabbix: TLSPSKIdentity is required by TLSPSK")
+	}
+	hexKey := o.TLSPSK
+	if hexKey == "" && o.TLSPSKFile != "" {
+		b, err := os.ReadFile(o.TLSPSKFile)
+		if err != nil {
+			return nil, err
+		}
+		hexKey = strings.TrimSpace(string(b))
+	}
+	// at least 128 bits, as required by Zabbix
+	if key, err = hex.DecodeString(hexKey); err != nil || len(key) < 16 {
+		return nil, errors.New("zabbix: TLSPSK must hold at least 32 hexadecimal digits")
+	}
+	return
+}
+
+// check validates the options before the first connection
+func (o *TLSOptions) check() error {
+	switch o.TLSConnect {
+	case "", TLSUnencrypted:
+	case TLSPSK:
+		if _, err := o.psk(); err != nil {
+			return err
+		}
+		if o.PSKDial == nil {
+			return ErrPSKUnsupported
+		}
+	case TLSCert:
+		if o.TLSConfig == nil {
+			return errors.New("zabbix: TLSConfig is required by TLSCert")
+		}
+	default:
+		return fmt.Errorf("zabbix: unknown TLSConnect %q", o.TLSConnect)
+	}
+	return nil
+}
+
+// dial connects to addr according to the options
+func (o *TLSOptions) dial(ctx context.Context, addr string) (net.Conn, error) {
+	switch o.TLSConnect {
+	case TLSPSK:
+		key, err := o.psk()
+		if err != nil {
+			return nil, err
+		}
+		if o.PSKDial == nil {
+			return nil, ErrPSKUnsupported
+		}
+		return o.PSKDial(ctx, addr, o.TLSPSKIdentity, key)
+	case TLSCert:
+		c := o.TLSConfig.Clone()
+		if c.ServerName == "" {
+			c.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		d := tls.Dialer{Config: c}
+		return d.DialContext(ctx, "tcp", addr)
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// exchange sends request on a new connection to addr and returns the response, within timeout
+func (o *TLSOptions) exchange(ctx context.Context, addr string, timeout time.Duration, request []byte, compress bool) (response []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := o.dial(ctx, addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	// unblock reads and writes on cancellation
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err = writeFrame(conn, request, compress); err == nil {
+		response, err = readFrame(conn)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		} else if errors.Is(err, os.ErrDeadlineExceeded) {
+			// the connection deadline can expire just before ctx
+			err = context.DeadlineExceeded
+		}
+	}
+	return
+}
+
+// withDefaultPort adds port to addr if it has none
+func withDefaultPort(addr, port string) string {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return net.JoinHostPort(strings.Trim(addr, "[]"), port)
+	}
+	return addr
+}